@@ -0,0 +1,19 @@
+package fc
+
+import (
+	"encoding/base64"
+)
+
+// Binary is decoded byte string of binary formats, e.g. MessagePack
+// bin or CBOR byte string. Text encoders write it as base64 string.
+type Binary []byte
+
+// String returns base64 encoding of b.
+func (b Binary) String() string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Binary) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
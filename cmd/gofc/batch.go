@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+// glob returns files matching pattern and the base directory of pattern,
+// which is the longest leading path without wildcards.
+// In addition to filepath.Match syntax '**' matches any number of directories.
+func glob(pattern string) (string, []string, error) {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	i := len(parts)
+	for k, part := range parts {
+		if strings.ContainsAny(part, `*?[\`) {
+			i = k
+			break
+		}
+	}
+	if i == len(parts) {
+		if _, err := os.Stat(pattern); err != nil {
+			return "", nil, nil
+		}
+		return filepath.Dir(pattern), []string{pattern}, nil
+	}
+
+	base := strings.Join(parts[:i], "/")
+	if base == "" && strings.HasPrefix(pattern, "/") {
+		base = "/"
+	} else if base == "" {
+		base = "."
+	}
+	base = filepath.FromSlash(base)
+
+	var files []string
+	err := filepath.Walk(base, func(file string, info os.FileInfo, err error) error {
+		if err != nil && file == base && os.IsNotExist(err) {
+			// nothing matches
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		if globMatch(parts[i:], strings.Split(filepath.ToSlash(rel), "/")) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, errors.Annotatef(err, "cannot list files matching '%s'", pattern)
+	}
+	return base, files, nil
+}
+
+func globMatch(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		return globMatch(pattern[1:], parts) || (len(parts) > 0 && globMatch(pattern, parts[1:]))
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && globMatch(pattern[1:], parts[1:])
+}
+
+// batch converts all files matching batch pattern in parallel.
+func batch(conf *config) error {
+	if conf.input != "" || conf.output != "" || conf.inplace || conf.watch {
+		usage(errors.New("-batch cannot be used with -in, -out, -inplace or -watch"))
+	}
+	if conf.outDir == "" {
+		usage(errors.New("-batch requires output directory"))
+	}
+	if conf.encoder == nil && conf.outExt != "" {
+		conf.encoder = &coderConfig{name: conf.outExt}
+	}
+	if conf.encoder == nil {
+		usage(errors.New("output encoder is not set"))
+	}
+	ext := conf.outExt
+	if ext == "" && conf.encoder.name == "tpl" {
+		usage(errors.New("-batch with tpl encoder requires output extension"))
+	} else if ext == "" {
+		ext = conf.encoder.name
+	}
+
+	base, files, err := glob(conf.batch)
+	if err != nil {
+		return errors.Trace(err)
+	} else if len(files) == 0 {
+		return errors.Errorf("batch: no files match '%s'", conf.batch)
+	}
+
+	jobs := conf.jobs
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	errs := make([]error, len(files))
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				errs[i] = batchFile(conf, base, files[i], ext)
+			}
+		}()
+	}
+	for i := range files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", files[i], err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("batch: %d of %d files failed:\n%s", len(failures), len(files), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+func batchFile(conf *config, base, file, ext string) error {
+	rel, err := filepath.Rel(base, file)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	c := *conf
+	c.input = file
+	c.output = filepath.Join(conf.outDir, strings.TrimSuffix(rel, filepath.Ext(rel))+"."+ext)
+	if c.decoder == nil {
+		c.decoder = &coderConfig{name: fc.CoderForPath(file)}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(c.output), 0755); err != nil {
+		return errors.Annotatef(err, "cannot create output directory")
+	}
+	return render(recoder, &c)
+}
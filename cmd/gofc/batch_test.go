@@ -0,0 +1,94 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"**/*.yml", "a.yml", true},
+		{"**/*.yml", "a/b/c.yml", true},
+		{"**/*.yml", "a/b/c.json", false},
+		{"a/**/c.yml", "a/c.yml", true},
+		{"a/**/c.yml", "a/b/b/c.yml", true},
+		{"a/**/c.yml", "b/c.yml", false},
+		{"a/**", "a/b/c.yml", true},
+		{"a/**", "b/c.yml", false},
+		{"a/*.yml", "a/b/c.yml", false},
+		{"a/b/c.yml", "a/b/c.yml", true},
+		{"a/b/c.yml", "a/b/d.yml", false},
+	} {
+		require.Equal(t, c.match, globMatch(strings.Split(c.pattern, "/"), strings.Split(c.path, "/")), "%s ~ %s", c.pattern, c.path)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofc-batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, file := range []string{"a.yml", "a/b.yml", "a/b/c.yml", "a/b/c.json", "d/c.yml"} {
+		file = filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, ioutil.WriteFile(file, nil, 0644))
+	}
+
+	for _, c := range []struct {
+		pattern string
+		base    string
+		files   []string
+	}{
+		{"**/*.yml", "", []string{"a/b/c.yml", "a/b.yml", "a.yml", "d/c.yml"}},
+		{"a/**/c.yml", "a", []string{"a/b/c.yml"}},
+		{"a/**", "a", []string{"a/b/c.json", "a/b/c.yml", "a/b.yml"}},
+		{"a/b/c.yml", "a/b", []string{"a/b/c.yml"}},
+		{"a/missing.yml", "", nil},
+		{"**/*.toml", "", nil},
+		{"missing/**/*.yml", "", nil},
+	} {
+		base, files, err := glob(filepath.Join(dir, c.pattern))
+		require.NoError(t, err, c.pattern)
+		if c.files == nil {
+			require.Empty(t, files, c.pattern)
+			continue
+		}
+		require.Equal(t, filepath.Join(dir, c.base), base, c.pattern)
+		var rel []string
+		for _, file := range files {
+			r, err := filepath.Rel(dir, file)
+			require.NoError(t, err)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		require.Equal(t, c.files, rel, c.pattern)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofc-batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "in", "a"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "in", "a", "ok.yml"), []byte("a: 1\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "in", "bad.yml"), []byte("a: [\n"), 0644))
+
+	err = batch(&config{
+		batch:   filepath.Join(dir, "in", "**", "*.yml"),
+		outDir:  filepath.Join(dir, "out"),
+		encoder: &coderConfig{name: "json"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "batch: 1 of 2 files failed:\n"+filepath.Join(dir, "in", "bad.yml")+": ")
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "out", "a", "ok.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a": 1}`, string(out))
+}
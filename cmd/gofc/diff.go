@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+func runDiff(args []string) error {
+	var decoder *coderConfig
+	var files []string
+	format := "text"
+	var err error
+	for len(args) > 0 {
+		switch args[0] {
+		case "-i":
+			args, err = readCoderConfig(&decoder, args[1:])
+			if err != nil {
+				usage(errors.Trace(err))
+			}
+		case "-format":
+			format, args = readOptionArg(args)
+		default:
+			if len(args[0]) > 0 && args[0][0] == '-' {
+				usage(errors.Errorf("unknown diff argument '%s'", args[0]))
+			}
+			files = append(files, args[0])
+			args = args[1:]
+		}
+	}
+	if len(files) != 2 {
+		usage(errors.New("diff: expecting two files"))
+	}
+
+	a, err := decodeFile(files[0], decoder)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b, err := decodeFile(files[1], decoder)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	changes := fc.Diff(a, b)
+	switch format {
+	case "text":
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	case "json":
+		res := make([]map[string]interface{}, 0, len(changes))
+		for _, c := range changes {
+			e := map[string]interface{}{
+				"op":   c.Op,
+				"path": c.PathString(),
+			}
+			if c.Op != fc.DiffAdd {
+				e["from"] = c.From
+			}
+			if c.Op != fc.DiffRemove {
+				e["to"] = c.To
+			}
+			res = append(res, e)
+		}
+		err = printJSON(res)
+	case "patch":
+		err = printJSON(fc.JSONPatch(changes))
+	default:
+		usage(errors.Errorf("diff: unknown format '%s', supported formats: text, json, patch", format))
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return errors.Annotatef(encoder.Encode(v), "cannot encode JSON")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+// writeFileAtomic writes data to temporary file and renames it
+// to path, so that failed write never leaves truncated file behind.
+// Permissions of existing file are preserved.
+func writeFileAtomic(path string, data []byte) (err error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Annotatef(err, "cannot create temporary file")
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return errors.Annotatef(err, "cannot write temporary file")
+	}
+	if err = tmp.Sync(); err != nil {
+		return errors.Annotatef(err, "cannot sync temporary file")
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return errors.Annotatef(err, "cannot set permissions of temporary file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Annotatef(err, "cannot close temporary file")
+	}
+	return errors.Annotatef(os.Rename(tmp.Name(), path), "cannot rename temporary file")
+}
+
+// decodeFile decodes file with decoder,
+// if decoder is empty, it's selected by file extension.
+func decodeFile(path string, decoder *coderConfig) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot open input file")
+	}
+	defer file.Close()
+
+	conf := &fc.Config{
+		Decoder: fc.CoderForPath(path),
+		Input:   file,
+	}
+	if decoder != nil {
+		conf.Decoder, conf.DecoderArgs = decoder.name, decoder.args
+	}
+	data, _, err := recoder.Decode(conf)
+	return data, errors.Annotatef(err, "cannot decode '%s'", path)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+func runLint(args []string) error {
+	conf := &fc.LintConfig{}
+	for len(args) > 0 {
+		switch args[0] {
+		case "-sample":
+			conf.Sample, args = readOptionArg(args)
+		case "-schema":
+			conf.Schema, args = readOptionArg(args)
+		default:
+			if len(args[0]) > 0 && args[0][0] == '-' {
+				usage(errors.Errorf("unknown lint argument '%s'", args[0]))
+			}
+			conf.Templates = append(conf.Templates, args[0])
+			args = args[1:]
+		}
+	}
+	if len(conf.Templates) == 0 {
+		usage(errors.New("lint: no templates specified"))
+	}
+
+	issues, err := recoder.Lint(conf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+	if len(issues) > 0 {
+		return errors.Errorf("lint: %d issue(s) found", len(issues))
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spirius/fc"
 
@@ -19,22 +25,116 @@ func usage(err error) {
 	fmt.Fprintf(os.Stderr, `gofc - structured data decoder/encoder
 
 Usage:
-gofc -i DECODER [ARG1, [...]] -o ENCODER [ARG1, [...]]
+gofc [-i DECODER [ARG1, [...]]] [-o ENCODER [ARG1, [...]]] [-in FILE] [-out FILE | -inplace] [-schema FILE] [-cue FILE] [-timeout DURATION] [-watch [-exec CMD]]
+gofc [-i DECODER [ARG1, [...]]] -o ENCODER [ARG1, [...]] [-schema FILE] [-cue FILE] -batch GLOB -outdir DIR [-outext EXT] [-jobs N]
+gofc get|set|del [-i DECODER [ARG1, [...]]] [-o ENCODER [ARG1, [...]]] [-in FILE] [-out FILE | -inplace] PATH [VALUE]
+gofc diff [-i DECODER [ARG1, [...]]] [-format text|json|patch] FILE1 FILE2
+gofc lint [-sample FILE] [-schema FILE] TEMPLATE [...]
+gofc test [-update] [PATH [...]]
+gofc validate -schema FILE | -cue FILE [-i DECODER [ARG1, [...]]] [FILE [...]]
 
 Options:
  -i            - input decoder
  -o            - output encoder
+ -in FILE      - read input from FILE instead of stdin, decoder defaults to file extension,
+                 relative imports are resolved against FILE directory
+ -out FILE     - write output to FILE instead of stdout, encoder defaults to file extension
+ -inplace      - write output back to input file
+ -schema FILE  - validate decoded input against JSON Schema before encoding
+ -cue FILE     - unify decoded input with CUE schema before encoding, defaults are filled in
+ -timeout DUR  - abort rendering, including imports, after DUR (e.g. 30s)
+ -watch        - re-render on changes of input, templates, includes and imported files
+ -exec CMD     - shell command to run after each render in watch mode
+ -batch GLOB   - convert all files matching GLOB pattern, '**' matches any number of directories
+ -outdir DIR   - batch output directory, directory layout of input files is mirrored
+ -outext EXT   - batch output file extension, defaults to encoder name, required for tpl encoder
+ -jobs N       - number of parallel batch jobs, defaults to number of CPUs
  -check-update - check if new version is available
  -self-update  - update to latest version
 
+Commands:
+get PATH       - print value at PATH (e.g. .spec.containers[0].image)
+set PATH VALUE - set value at PATH, VALUE is parsed as YAML
+del PATH       - delete value at PATH
+                 input and output are handled as in conversion, output encoder
+                 defaults to input decoder (e.g. gofc set -in app.toml -inplace .image.tag v2),
+                 key order and comments of YAML documents are preserved
+diff           - compare two structured documents, possibly of different formats,
+                 exits with non-zero status if documents differ
+  -i DECODER   - decoder of both files, defaults to file extension
+  -format FMT  - output format: text (default), json or patch (RFC 6902 JSON Patch)
+lint           - check templates for syntax errors, unknown functions,
+                 unreachable defines and fields missing in sample input or schema
+  -sample FILE - sample input file, decoder is selected by file extension
+  -schema FILE - JSON Schema of the input
+test           - run golden-file test cases (*.gofc-test.yml) found in PATHs
+  -update      - regenerate golden files instead of comparing
+validate       - validate FILEs, or stdin, against JSON Schema (draft 2019-09 or 2020-12)
+                 or CUE schema and print invalid values, exits with non-zero status if validation fails
+  -schema FILE - JSON Schema file, decoder is selected by file extension
+  -cue FILE    - CUE schema file
+  -i DECODER   - decoder of input, defaults to file extension
+
 Supported coders:
 json, j        - JSON decoder/encoder
 yaml, yml, y   - YANL decoder/encoder
+  multi        - decode multi-document stream into list of documents
 hcl, h         - HCL decoder/encoder, only HCL Attributes are supported, blocks are ignored
 toml, t        - TOML decoder/encoder
 null, n        - null decoder
+jsonschema     - JSON Schema encoder, infers schema from input
+  multi        - treat input list as list of sample documents
+  enum=N       - max number of distinct repeated string values turned into enum (default: 5, 0 disables)
+go             - Go encoder, generates Go type definitions from input
+  package=NAME - package name (default: main)
+  type=NAME    - root type name (default: Config)
+  tags=T1,T2   - struct tags (default: json,yaml,toml)
+  multi        - treat input list as list of sample documents
+ini            - INI decoder/encoder, sections are decoded into nested maps
+properties     - Java properties decoder/encoder, dotted keys are decoded into nested maps
+  flat         - keep dotted keys as is
+dotenv, env    - .env decoder and environment variables encoder, nested keys are flattened
+                 into SCREAMING_SNAKE names (e.g.: eval "$(gofc -in app.yml -o env APP_)")
+  noexpand     - do not expand ${VAR} references (decoder)
+  PREFIX       - prefix of variable names (encoder)
+  sep=SEP      - separator of nested keys (encoder, default: _)
+  export       - write 'export KEY=value' lines (encoder)
+msgpack        - MessagePack decoder/encoder, binary values are base64 strings in text formats
+cbor           - CBOR decoder/encoder, timestamp tags are decoded as time, other tags are skipped
+  integers     - write whole numbers as integers, e.g. for JSON input (msgpack and cbor encoders)
+proto          - Protocol Buffers decoder/encoder, messages are converted using proto3 JSON mapping
+                 (e.g.: gofc -in app.textproto -i proto descriptor=app.pb message=app.Config text -o yaml)
+  descriptor=F - FileDescriptorSet file, generated with protoc --include_imports -o F
+  message=NAME - full name of the message, e.g. app.Config
+  text         - use text format instead of binary
+  defaults     - output fields with default values (decoder)
+hocon          - HOCON decoder, supports includes, substitutions and object merging
+jsonnet        - Jsonnet decoder, evaluates input and decodes resulting JSON
+  ext-str=N=V  - external string variable, value is read from environment if '=V' is omitted
+  ext-code=N=C - external variable with Jsonnet code value
+  tla-str=N=V  - top-level string argument, value is read from environment if '=V' is omitted
+  tla-code=N=C - top-level argument with Jsonnet code value
+  jpath=DIR    - library search path, can be set multiple times
+cue            - CUE decoder, evaluates input into concrete values
+  expr=PATH    - export value at PATH instead of the whole input (e.g.: expr=spec.config)
+frontmatter    - Markdown/HTML front matter decoder, YAML (---), TOML (+++) or JSON front matter
+                 is decoded as data and document body is available as metadata
+plist          - Apple property list decoder/encoder, XML and binary formats
+  binary       - write binary format instead of XML (encoder)
+  integers     - write whole numbers as integers, e.g. for JSON input (encoder)
+  data=KEY     - write base64 strings of KEY as data, can be set multiple times (encoder)
+  date=KEY     - write RFC 3339 strings of KEY as dates, can be set multiple times (encoder)
+xlsx           - Excel workbook decoder/encoder, sheets are lists of rows keyed by header row
+  sheet=NAME   - decode sheet NAME instead of the first one, or name of encoded sheet (default: Sheet1)
+  all          - decode all sheets into map keyed by sheet name (decoder)
+  columns=A,B  - order of first columns, other columns are sorted by name (encoder)
 tpl            - template encoder, provides golang template based engine
   path         - template file path (e.g.: gofc -i n -o tpl config.tpl)
+  delims=L R   - template action delimiters (e.g.: gofc -i n -o tpl config.tpl "delims=[[ ]]")
+
+Plugins:
+Executables named gofc-coder-NAME in GOFC_PLUGIN_PATH or PATH directories are registered
+as NAME decoder and encoder, coder arguments are passed to the plugin.
 
 For more information and examples, please visit https://github.com/spirius/fc
 
@@ -50,6 +150,25 @@ type coderConfig struct {
 type config struct {
 	decoder *coderConfig
 	encoder *coderConfig
+
+	input   string
+	output  string
+	inplace bool
+	schema  *fc.Schema
+	cue     *fc.CUESchema
+	timeout time.Duration
+
+	// schemaPath and cuePath are reloaded on changes in watch mode
+	schemaPath string
+	cuePath    string
+
+	watch     bool
+	watchExec string
+
+	batch  string
+	outDir string
+	outExt string
+	jobs   int
 }
 
 func readCoderConfig(c **coderConfig, args []string) ([]string, error) {
@@ -69,6 +188,14 @@ func readCoderConfig(c **coderConfig, args []string) ([]string, error) {
 	return nil, nil
 }
 
+// readOptionArg reads value argument of an option.
+func readOptionArg(args []string) (string, []string) {
+	if len(args) < 2 {
+		usage(errors.Errorf("%s: value is not set", args[0]))
+	}
+	return args[1], args[2:]
+}
+
 // Version is set during build.
 var Version = "local-build"
 
@@ -111,11 +238,41 @@ func selfUpdate() error {
 	return nil
 }
 
+// commands are gofc sub-commands, selected by first argument.
+// recoder is the default recoder, set on start.
+var recoder *fc.Recoder
+
+var commands = map[string]func(args []string) error{
+	"del":      runDel,
+	"diff":     runDiff,
+	"get":      runGet,
+	"lint":     runLint,
+	"set":      runSet,
+	"test":     runTest,
+	"validate": runValidate,
+}
+
 func main() {
 	var conf config
 	var err error
 
+	if recoder, err = fc.Default(); err != nil {
+		fatal(err)
+	}
+	if err = recoder.LoadPlugins(fc.PluginDirs()...); err != nil {
+		fatal(err)
+	}
+
 	args := os.Args[1:]
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			if err = cmd(args[1:]); err != nil {
+				fatal(err)
+			}
+			return
+		}
+	}
+
 	for len(args) > 0 {
 		e := args[0]
 		switch e {
@@ -134,6 +291,48 @@ func main() {
 				usage(errors.New("output encoder is already set"))
 			}
 			args, err = readCoderConfig(&conf.encoder, args[1:])
+		case "-in":
+			conf.input, args = readOptionArg(args)
+		case "-out":
+			conf.output, args = readOptionArg(args)
+		case "-inplace":
+			conf.inplace = true
+			args = args[1:]
+		case "-schema":
+			conf.schemaPath, args = readOptionArg(args)
+			if conf.schema, err = recoder.LoadSchema(conf.schemaPath); err != nil {
+				fatal(err)
+			}
+		case "-cue":
+			conf.cuePath, args = readOptionArg(args)
+			if conf.cue, err = recoder.LoadCUESchema(conf.cuePath); err != nil {
+				fatal(err)
+			}
+		case "-timeout":
+			var timeout string
+			timeout, args = readOptionArg(args)
+			if conf.timeout, err = time.ParseDuration(timeout); err == nil && conf.timeout <= 0 {
+				err = errors.New("-timeout: timeout must be positive")
+			}
+		case "-watch":
+			conf.watch = true
+			args = args[1:]
+		case "-exec":
+			conf.watchExec, args = readOptionArg(args)
+		case "-batch":
+			conf.batch, args = readOptionArg(args)
+		case "-outdir":
+			conf.outDir, args = readOptionArg(args)
+		case "-outext":
+			conf.outExt, args = readOptionArg(args)
+		case "-jobs":
+			if len(args) < 2 {
+				usage(errors.New("-jobs: number of jobs is not set"))
+			}
+			if conf.jobs, err = strconv.Atoi(args[1]); err == nil && conf.jobs < 1 {
+				err = errors.New("-jobs: number of jobs must be positive")
+			}
+			args = args[2:]
 		case "-self-update":
 			err = selfUpdate()
 			if err != nil {
@@ -157,26 +356,100 @@ func main() {
 		}
 	}
 
-	if conf.decoder == nil {
+	if conf.batch != "" {
+		if err = batch(&conf); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if conf.inplace {
+		if conf.input == "" {
+			usage(errors.New("-inplace requires input file"))
+		}
+		if conf.output != "" {
+			usage(errors.New("-inplace cannot be used with output file"))
+		}
+		if conf.watch {
+			usage(errors.New("-inplace cannot be used with -watch"))
+		}
+		conf.output = conf.input
+	}
+
+	// infer coders from file extensions
+	if conf.decoder == nil && conf.input != "" {
+		conf.decoder = &coderConfig{name: fc.CoderForPath(conf.input)}
+	}
+	if conf.encoder == nil && conf.output != "" {
+		conf.encoder = &coderConfig{name: fc.CoderForPath(conf.output)}
+	}
+	if conf.decoder == nil || conf.decoder.name == "" {
 		usage(errors.New("input decoder is not set"))
 	}
-	if conf.encoder == nil {
+	if conf.encoder == nil || conf.encoder.name == "" {
 		usage(errors.New("output encoder is not set"))
 	}
 
-	cConf := &fc.Config{
+	if conf.watch {
+		err = watch(&conf)
+	} else {
+		err = render(recoder, &conf)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// render runs the recoder with provided configuration.
+func render(r *fc.Recoder, conf *config) error {
+	var input io.Reader = os.Stdin
+	var baseDir string
+	if conf.input != "" {
+		baseDir = filepath.Dir(conf.input)
+		file, err := os.Open(conf.input)
+		if err != nil {
+			return errors.Annotatef(err, "cannot open input file")
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var buf bytes.Buffer
+	var output io.Writer = os.Stdout
+	if conf.output != "" {
+		output = &buf
+	}
+
+	ctx := context.Background()
+	if conf.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.timeout)
+		defer cancel()
+	}
+
+	err := r.RunContext(ctx, &fc.Config{
 		Decoder:     conf.decoder.name,
 		DecoderArgs: conf.decoder.args,
 		Encoder:     conf.encoder.name,
 		EncoderArgs: conf.encoder.args,
-		Input:       os.Stdin,
-		Output:      os.Stdout,
+		Input:       input,
+		Output:      output,
+		BaseDir:     baseDir,
+		Schema:      conf.schema,
+		CUE:         conf.cue,
+	})
+	if err != nil {
+		return errors.Trace(err)
 	}
 
-	err = fc.DefaultRecoder.Run(cConf)
-	if err == nil {
-		return
+	if conf.output != "" {
+		return errors.Annotatef(writeFileAtomic(conf.output, buf.Bytes()), "cannot write output file")
 	}
+	return nil
+}
+
+// fatal prints the error with stack trace, if available, and exits.
+func fatal(err error) {
 	var trace []string
 	traceableError, ok := err.(*errors.Err)
 
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+// readEditCoderConfig reads coder config of get/set/del commands,
+// coder arguments end at the path, see isPathArg.
+func readEditCoderConfig(c **coderConfig, args []string) ([]string, error) {
+	rest, err := readCoderConfig(c, args)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range (*c).args {
+		if isPathArg(v) {
+			rest = append(append([]string{}, (*c).args[k:]...), rest...)
+			if (*c).args = (*c).args[:k]; k == 0 {
+				(*c).args = nil
+			}
+			break
+		}
+	}
+	return rest, nil
+}
+
+// isPathArg reports whether argument is a path, i.e. starts with '.'
+// or '[', file paths starting with './' or '../' are not paths.
+func isPathArg(arg string) bool {
+	return (strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "[")) &&
+		!strings.HasPrefix(arg, "./") && !strings.HasPrefix(arg, "../")
+}
+
+// readEditConfig reads options of get/set/del commands
+// and returns remaining positional arguments. Arguments
+// after '--' are always positional.
+func readEditConfig(cmd string, args []string) (*config, []string) {
+	conf := &config{}
+	var positional []string
+	var err error
+	for len(args) > 0 {
+		switch args[0] {
+		case "-i":
+			args, err = readEditCoderConfig(&conf.decoder, args[1:])
+		case "-o":
+			args, err = readEditCoderConfig(&conf.encoder, args[1:])
+		case "-in":
+			conf.input, args = readOptionArg(args)
+		case "-out":
+			conf.output, args = readOptionArg(args)
+		case "-inplace":
+			conf.inplace = true
+			args = args[1:]
+		case "--":
+			positional = append(positional, args[1:]...)
+			args = nil
+		default:
+			if len(args[0]) > 1 && args[0][0] == '-' && (args[0][1] < '0' || args[0][1] > '9') {
+				usage(errors.Errorf("unknown %s argument '%s'", cmd, args[0]))
+			}
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+		if err != nil {
+			usage(errors.Trace(err))
+		}
+	}
+
+	if conf.inplace {
+		if conf.input == "" || conf.output != "" {
+			usage(errors.Errorf("%s: -inplace requires input file and no output file", cmd))
+		}
+		conf.output = conf.input
+	}
+	if conf.decoder == nil && conf.input != "" {
+		conf.decoder = &coderConfig{name: fc.CoderForPath(conf.input)}
+	}
+	if conf.decoder == nil {
+		usage(errors.Errorf("%s: input decoder is not set", cmd))
+	}
+	if conf.encoder == nil {
+		conf.encoder = &coderConfig{name: conf.decoder.name}
+		if conf.output != "" {
+			conf.encoder.name = fc.CoderForPath(conf.output)
+		}
+		if _, ok := recoder.Encoders[conf.encoder.name]; !ok {
+			conf.encoder.name = "json"
+		}
+	}
+	return conf, positional
+}
+
+func readInput(conf *config) ([]byte, error) {
+	if conf.input != "" {
+		data, err := ioutil.ReadFile(conf.input)
+		return data, errors.Annotatef(err, "cannot read input file")
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	return data, errors.Annotatef(err, "cannot read input")
+}
+
+func decodeInput(conf *config) (interface{}, error) {
+	input, err := readInput(conf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, _, err := recoder.Decode(&fc.Config{
+		Decoder:     conf.decoder.name,
+		DecoderArgs: conf.decoder.args,
+		Input:       bytes.NewBuffer(input),
+	})
+	return data, errors.Trace(err)
+}
+
+func encodeOutput(conf *config, data interface{}) error {
+	var buf bytes.Buffer
+	err := recoder.Encode(&fc.Config{
+		Encoder:     conf.encoder.name,
+		EncoderArgs: conf.encoder.args,
+		Output:      &buf,
+	}, data, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return writeOutput(conf, buf.Bytes())
+}
+
+func writeOutput(conf *config, data []byte) error {
+	if conf.output != "" {
+		return errors.Annotatef(writeFileAtomic(conf.output, data), "cannot write output file")
+	}
+	_, err := os.Stdout.Write(data)
+	return errors.Trace(err)
+}
+
+// yamlCoders are names of YAML coder.
+var yamlCoders = map[string]bool{"yaml": true, "yml": true, "y": true}
+
+// editYAML reports whether document is edited as YAML nodes, which
+// keeps key order and comments. Documents decoded with arguments, e.g.
+// 'multi', and conversions to other formats are re-encoded. Editing of
+// multi-document streams as nodes fails, so that documents are not lost.
+func editYAML(conf *config) bool {
+	return yamlCoders[conf.decoder.name] && yamlCoders[conf.encoder.name] &&
+		len(conf.decoder.args) == 0 && len(conf.encoder.args) == 0
+}
+
+func readPathArgs(cmd string, positional []string, n int) []interface{} {
+	if len(positional) != n {
+		usage(errors.Errorf("%s: unexpected number of arguments", cmd))
+	}
+	path, err := fc.ParsePath(positional[0])
+	if err != nil {
+		usage(errors.Trace(err))
+	}
+	return path
+}
+
+func runGet(args []string) error {
+	conf, positional := readEditConfig("get", args)
+	path := readPathArgs("get", positional, 1)
+
+	data, err := decodeInput(conf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	value, err := fc.GetPath(data, path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// scalars are printed as is, so they can be used in shell scripts
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		return encodeOutput(conf, value)
+	case nil:
+		fmt.Println("null")
+	default:
+		fmt.Println(v)
+	}
+	return nil
+}
+
+func runSet(args []string) error {
+	conf, positional := readEditConfig("set", args)
+	path := readPathArgs("set", positional, 2)
+
+	// value is parsed as YAML, so that numbers,
+	// booleans and inline structures are supported
+	value, _, err := recoder.Decode(&fc.Config{
+		Decoder: "yaml",
+		Input:   bytes.NewBufferString(positional[1]),
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot parse value")
+	}
+
+	if editYAML(conf) {
+		input, err := readInput(conf)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if input, err = fc.SetYAMLPath(input, path, value); err != nil {
+			return errors.Trace(err)
+		}
+		return writeOutput(conf, input)
+	}
+	data, err := decodeInput(conf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if data, err = fc.SetPath(data, path, value); err != nil {
+		return errors.Trace(err)
+	}
+	return encodeOutput(conf, data)
+}
+
+func runDel(args []string) error {
+	conf, positional := readEditConfig("del", args)
+	path := readPathArgs("del", positional, 1)
+
+	if editYAML(conf) {
+		input, err := readInput(conf)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if input, err = fc.DeleteYAMLPath(input, path); err != nil {
+			return errors.Trace(err)
+		}
+		return writeOutput(conf, input)
+	}
+	data, err := decodeInput(conf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if data, err = fc.DeletePath(data, path); err != nil {
+		return errors.Trace(err)
+	}
+	return encodeOutput(conf, data)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spirius/fc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	if recoder, err = fc.Default(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestReadEditConfig(t *testing.T) {
+	conf, positional := readEditConfig("get", []string{"-i", "yaml", ".a"})
+	require.Equal(t, &coderConfig{name: "yaml"}, conf.decoder)
+	require.Equal(t, []string{".a"}, positional)
+
+	conf, positional = readEditConfig("set", []string{"-i", "yaml", "multi", "[0].a", "5"})
+	require.Equal(t, &coderConfig{name: "yaml", args: []string{"multi"}}, conf.decoder)
+	require.Equal(t, []string{"[0].a", "5"}, positional)
+
+	conf, positional = readEditConfig("get", []string{"-i", "json", "-o", "tpl", "./item.tpl", "."})
+	require.Equal(t, &coderConfig{name: "tpl", args: []string{"./item.tpl"}}, conf.encoder)
+	require.Equal(t, []string{"."}, positional)
+
+	conf, positional = readEditConfig("set", []string{"-i", "yaml", "--", ".a", "-1"})
+	require.Equal(t, &coderConfig{name: "yaml"}, conf.decoder)
+	require.Equal(t, []string{".a", "-1"}, positional)
+}
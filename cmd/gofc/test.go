@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+func runTest(args []string) error {
+	var update bool
+	var paths []string
+	for _, arg := range args {
+		switch arg {
+		case "-update":
+			update = true
+		default:
+			if len(arg) > 0 && arg[0] == '-' {
+				usage(errors.Errorf("unknown test argument '%s'", arg))
+			}
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := recoder.FindTestCases(paths...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var failed int
+	for _, file := range files {
+		res, err := runTestCase(file, update)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL    %s\n%s\n", file, err)
+		case res.Updated:
+			fmt.Printf("UPDATED %s\n", file)
+		case res.Passed():
+			fmt.Printf("ok      %s\n", file)
+		default:
+			failed++
+			fmt.Printf("FAIL    %s\n%s", file, res.Diff)
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("test: %d of %d test cases failed", failed, len(files))
+	}
+	return nil
+}
+
+func runTestCase(file string, update bool) (*fc.TestResult, error) {
+	tc, err := recoder.LoadTestCase(file)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return recoder.RunTest(tc, update)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+func runValidate(args []string) error {
+	var decoder *coderConfig
+	var schemaPath, cuePath string
+	var files []string
+	var err error
+	for len(args) > 0 {
+		switch args[0] {
+		case "-i":
+			args, err = readCoderConfig(&decoder, args[1:])
+			if err != nil {
+				usage(errors.Trace(err))
+			}
+		case "-schema":
+			schemaPath, args = readOptionArg(args)
+		case "-cue":
+			cuePath, args = readOptionArg(args)
+		default:
+			if len(args[0]) > 0 && args[0][0] == '-' {
+				usage(errors.Errorf("unknown validate argument '%s'", args[0]))
+			}
+			files = append(files, args[0])
+			args = args[1:]
+		}
+	}
+	if schemaPath == "" && cuePath == "" {
+		usage(errors.New("validate: schema is not set"))
+	}
+	if len(files) == 0 && decoder == nil {
+		usage(errors.New("validate: input decoder is not set"))
+	}
+
+	validate, err := loadValidator(schemaPath, cuePath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var failed int
+	if len(files) == 0 {
+		if !reportValidation("-", validateInput(&config{decoder: decoder}, validate)) {
+			failed++
+		}
+	}
+	for _, file := range files {
+		conf := &config{decoder: decoder, input: file}
+		if decoder == nil {
+			conf.decoder = &coderConfig{name: fc.CoderForPath(file)}
+		}
+		if !reportValidation(file, validateInput(conf, validate)) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// validateInput decodes and validates input of conf,
+// violations in YAML input are located in the source.
+func validateInput(conf *config, validate func(data interface{}) error) error {
+	input, err := readInput(conf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, _, err := recoder.Decode(&fc.Config{
+		Decoder:     conf.decoder.name,
+		DecoderArgs: conf.decoder.args,
+		Input:       bytes.NewReader(input),
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot decode input")
+	}
+	err = validate(data)
+	if verr, ok := errors.Cause(err).(*fc.ValidationError); ok && yamlCoders[conf.decoder.name] {
+		args := conf.decoder.args
+		verr.LocateYAML(input, len(args) == 1 && args[0] == "multi")
+	}
+	return err
+}
+
+// loadValidator loads JSON Schema and CUE schema, if set,
+// and returns function validating data against both.
+func loadValidator(schemaPath, cuePath string) (func(data interface{}) error, error) {
+	var schema *fc.Schema
+	var cue *fc.CUESchema
+	var err error
+	if schemaPath != "" {
+		if schema, err = recoder.LoadSchema(schemaPath); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if cuePath != "" {
+		if cue, err = recoder.LoadCUESchema(cuePath); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return func(data interface{}) error {
+		if cue != nil {
+			if data, err = cue.Unify(data); err != nil {
+				return err
+			}
+		}
+		if schema != nil {
+			return schema.Validate(data)
+		}
+		return nil
+	}, nil
+}
+
+// reportValidation prints validation errors of file
+// and returns true, if file is valid.
+func reportValidation(file string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if verr, ok := errors.Cause(err).(*fc.ValidationError); ok {
+		for _, v := range verr.Violations {
+			if v.Line > 0 {
+				// line is reported as part of file position
+				line := v.Line
+				v.Line = 0
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", file, line, v)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", file, v)
+			}
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spirius/fc"
+
+	"github.com/juju/errors"
+)
+
+// watchInterval is the polling interval of watched files.
+var watchInterval = time.Second
+
+// watcher tracks files accessed during rendering.
+// Tracked paths can be patterns, so that new
+// files matching pattern imports are detected too.
+type watcher struct {
+	// paths are watched paths with their
+	// state at the time they were accessed
+	paths map[string]string
+
+	// accessed are paths accessed by the current render
+	accessed map[string]string
+}
+
+func newWatcher() *watcher {
+	return &watcher{paths: make(map[string]string), accessed: make(map[string]string)}
+}
+
+// add records state of path before it's read,
+// so that changes saved during rendering are detected.
+func (w *watcher) add(path string) {
+	if _, ok := w.accessed[path]; !ok {
+		w.accessed[path] = pathState(path)
+	}
+}
+
+// done replaces watched paths with paths accessed by the render.
+// Failed render may stop before reading all files, so previously
+// watched paths are kept.
+func (w *watcher) done(failed bool) {
+	if failed {
+		for path, state := range w.paths {
+			if _, ok := w.accessed[path]; !ok {
+				w.accessed[path] = state
+			}
+		}
+	}
+	w.paths, w.accessed = w.accessed, make(map[string]string)
+}
+
+// changed reports whether any of watched files has changed
+// since it was accessed.
+func (w *watcher) changed() bool {
+	for path, state := range w.paths {
+		if pathState(path) != state {
+			return true
+		}
+	}
+	return false
+}
+
+// pathState returns state of all files matching pattern.
+func pathState(pattern string) string {
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) == 0 {
+		files = []string{pattern}
+	}
+	var state []string
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			state = append(state, fmt.Sprintf("%s:%d:%d", file, info.Size(), info.ModTime().UnixNano()))
+		} else {
+			state = append(state, file+":missing")
+		}
+	}
+	sort.Strings(state)
+	return strings.Join(state, "\n")
+}
+
+// watch renders the output every time
+// one of the source files is changed.
+func watch(conf *config) error {
+	if conf.input == "" {
+		return errors.New("watch: input file is not set")
+	}
+
+	// input, template and schemas are watched
+	// even if render fails before reading them
+	var sources []string
+	if conf.encoder.name == "tpl" && len(conf.encoder.args) > 0 {
+		sources = append(sources, conf.encoder.args[0])
+	}
+	sources = append(sources, conf.input)
+	for _, path := range []string{conf.schemaPath, conf.cuePath} {
+		if path != "" {
+			sources = append(sources, path)
+		}
+	}
+
+	w := newWatcher()
+	r, err := fc.NewRecoder(fc.WithBuiltinCoders(), fc.WithFileAccessHook(w.add))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = r.LoadPlugins(fc.PluginDirs()...); err != nil {
+		return errors.Trace(err)
+	}
+
+	for {
+		for _, path := range sources {
+			if abs, err := filepath.Abs(path); err == nil {
+				w.add(abs)
+			}
+		}
+
+		// errors are reported but watching continues,
+		// so the next change can fix them
+		err := loadSchemas(r, conf)
+		if err == nil {
+			err = render(r, conf)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		} else if conf.watchExec != "" {
+			cmd := exec.Command("sh", "-c", conf.watchExec)
+			cmd.Stdout = os.Stderr
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				fmt.Fprintf(os.Stderr, "error: command '%s' failed: %s\n", conf.watchExec, err)
+			}
+		}
+		w.done(err != nil)
+
+		for !w.changed() {
+			time.Sleep(watchInterval)
+		}
+	}
+}
+
+// loadSchemas reloads schemas of conf, so that
+// their changes are applied on the next render.
+func loadSchemas(r *fc.Recoder, conf *config) (err error) {
+	if conf.schemaPath != "" {
+		if conf.schema, err = r.LoadSchema(conf.schemaPath); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if conf.cuePath != "" {
+		if conf.cue, err = r.LoadCUESchema(conf.cuePath); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofc-watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.yml")
+	pattern := filepath.Join(dir, "conf.d", "*.yml")
+	require.NoError(t, ioutil.WriteFile(input, []byte("a: 1\n"), 0644))
+
+	w := newWatcher()
+	w.add(input)
+	w.add(pattern)
+	w.done(false)
+	require.Contains(t, w.paths[input], input+":5:")
+	require.Equal(t, pattern+":missing", w.paths[pattern])
+	require.False(t, w.changed())
+
+	// changed file
+	require.NoError(t, ioutil.WriteFile(input, []byte("a: 10\n"), 0644))
+	require.True(t, w.changed())
+
+	// file changed during render, after it was accessed
+	w.add(input)
+	require.NoError(t, ioutil.WriteFile(input, []byte("a: 100\n"), 0644))
+	w.add(pattern)
+	w.done(false)
+	require.True(t, w.changed())
+	w.add(input)
+	w.add(pattern)
+	w.done(false)
+	require.False(t, w.changed())
+
+	// new file matching pattern
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf.d"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "conf.d", "b.yml"), nil, 0644))
+	require.True(t, w.changed())
+	require.Contains(t, pathState(pattern), filepath.Join(dir, "conf.d", "b.yml")+":0:")
+
+	// failed render keeps previously watched paths
+	include := filepath.Join(dir, "include.yml")
+	w.add(include)
+	w.done(true)
+	require.Len(t, w.paths, 3)
+	require.Contains(t, w.paths, pattern)
+
+	// successful render replaces them
+	w.add(input)
+	w.done(false)
+	require.Len(t, w.paths, 1)
+	require.False(t, w.changed())
+
+	// removed file
+	require.NoError(t, os.Remove(input))
+	require.Equal(t, input+":missing", pathState(input))
+	require.True(t, w.changed())
+}
@@ -0,0 +1,44 @@
+package fc
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/ugorji/go/codec"
+)
+
+// coderCBOR decodes and encodes CBOR. Timestamp tags are decoded
+// into time values, other unknown tags are skipped.
+type coderCBOR struct {
+	handle *codec.CborHandle
+}
+
+func (c *coderCBOR) Initialize() error {
+	c.handle = &codec.CborHandle{TimeRFC3339: true, SkipUnexpectedTags: true}
+	c.handle.Canonical = true
+	return nil
+}
+
+func (c *coderCBOR) Names() []string {
+	return []string{"cbor"}
+}
+
+func (c *coderCBOR) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("CBOR: invalid input argument '%s', no arguments expected", args[0])})
+	}
+	var out interface{}
+	if err := codec.NewDecoder(in, c.handle).Decode(&out); err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot decode CBOR")
+	}
+	return codecOutput(out), nil, nil
+}
+
+func (c *coderCBOR) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	integers, err := parseCodecArgs("CBOR", args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(codec.NewEncoder(out, c.handle).Encode(codecInput(in, integers)), "CBOR: cannot encode")
+}
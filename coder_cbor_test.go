@@ -0,0 +1,43 @@
+package fc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBOR(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "yaml",
+		Encoder: "cbor",
+		Input:   bytes.NewBufferString("port: 8080\nratio: 1.5\nname: app\n"),
+		Output:  &out,
+	}))
+	require.Equal(t, "a3646e616d656361707064706f7274191f9065726174696ffb3ff8000000000000", hex.EncodeToString(out.Bytes()))
+
+	// {"at": 0("2013-03-21T20:04:00Z"), "raw": h'0102', "n": 32(-1), "f": 1.0}
+	input, _ := hex.DecodeString("a4626174c074323031332d30332d32315432303a30343a30305a63726177420102616ed820206166f93c00")
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "cbor",
+		Encoder: "json",
+		Input:   bytes.NewBuffer(input),
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{"at": "2013-03-21T20:04:00Z", "raw": "AQI=", "n": -1, "f": 1}`, out.String())
+
+	// floats are kept apart from integers
+	data, _, err := testRecoder.Decode(&Config{Decoder: "cbor", Input: bytes.NewBuffer(input)})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), data.(map[string]interface{})["f"])
+	out.Reset()
+	require.NoError(t, testRecoder.Encode(&Config{Encoder: "cbor", Output: &out}, map[string]interface{}{"f": float64(1)}, nil))
+	require.Equal(t, "a16166fb3ff0000000000000", hex.EncodeToString(out.Bytes()))
+
+	out.Reset()
+	require.NoError(t, testRecoder.Encode(&Config{Encoder: "cbor", EncoderArgs: []string{"integers"}, Output: &out}, map[string]interface{}{"f": float64(1)}, nil))
+	require.Equal(t, "a1616601", hex.EncodeToString(out.Bytes()))
+}
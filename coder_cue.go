@@ -0,0 +1,185 @@
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	cuejson "cuelang.org/go/encoding/json"
+	"github.com/juju/errors"
+)
+
+// coderCUE evaluates CUE source into concrete data,
+// definitions and hidden fields are omitted.
+type coderCUE struct {
+	conv *Recoder
+}
+
+func (c *coderCUE) Initialize() error {
+	return nil
+}
+
+func (c *coderCUE) Names() []string {
+	return []string{"cue"}
+}
+
+func (c *coderCUE) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	return c.DecodeContext(context.Background(), in, args)
+}
+
+// DecodeContext is implemented to receive the input as is,
+// so positions in errors refer to the input file name.
+func (c *coderCUE) DecodeContext(ctx context.Context, in io.Reader, args []string) (interface{}, interface{}, error) {
+	var expr string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "expr="):
+			expr = strings.TrimPrefix(arg, "expr=")
+		default:
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("cue: invalid input argument '%s', supported arguments: 'expr=PATH'", arg)})
+		}
+	}
+	src, err := ioutil.ReadAll(&ctxReader{ctx: ctx, r: in})
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "cue: cannot read input")
+	}
+	name := inputFileName(in)
+	if name == "" {
+		name = "<stdin>"
+	}
+	v := cuecontext.New().CompileBytes(src, cue.Filename(name))
+	if expr != "" {
+		v = v.LookupPath(cue.ParsePath(expr))
+	}
+	if err = v.Validate(cue.Concrete(true)); err != nil {
+		return nil, nil, errors.Annotatef(cueError(nil, err), "cannot evaluate cue")
+	}
+	out, err := cueExport(v)
+	return out, nil, errors.Trace(err)
+}
+
+// CUESchema is a CUE value, which is unified with decoded data.
+// Unlike JSON Schema it can also fill in default values.
+type CUESchema struct {
+	ctx   *cue.Context
+	value cue.Value
+}
+
+// CompileCUESchema compiles CUE schema source,
+// name is used in error positions.
+func CompileCUESchema(name string, src []byte) (*CUESchema, error) {
+	ctx := cuecontext.New()
+	v := ctx.CompileBytes(src, cue.Filename(name))
+	if err := v.Err(); err != nil {
+		return nil, errors.Annotatef(cueError(nil, err), "invalid cue schema")
+	}
+	return &CUESchema{ctx: ctx, value: v}, nil
+}
+
+// LoadCUESchema reads and compiles CUE schema from path.
+func (r *Recoder) LoadCUESchema(path string) (*CUESchema, error) {
+	r.fileAccessed(path)
+	src, err := r.readFile(path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read cue schema")
+	}
+	return CompileCUESchema(path, src)
+}
+
+// Unify unifies data with the schema and returns the result
+// with default values filled in. If data conflicts with the
+// schema or result is not concrete, returned error is
+// *ValidationError.
+func (s *CUESchema) Unify(data interface{}) (interface{}, error) {
+	doc := normalizeValue(data)
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot encode data")
+	}
+	// data is converted via JSON, so whole numbers are unified as int
+	expr, err := cuejson.Extract("input", js)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot encode data")
+	}
+	v := s.value.Unify(s.ctx.BuildExpr(expr))
+	if err = v.Validate(cue.Concrete(true)); err != nil {
+		return nil, cueError(doc, err)
+	}
+	return cueExport(v)
+}
+
+// cueExport converts concrete value into data.
+func cueExport(v cue.Value) (interface{}, error) {
+	js, err := v.MarshalJSON()
+	if err != nil {
+		return nil, errors.Annotatef(cueError(nil, err), "cue: cannot export value")
+	}
+	var out interface{}
+	if err = json.Unmarshal(js, &out); err != nil {
+		return nil, errors.Annotatef(err, "cue: cannot decode result")
+	}
+	return out, nil
+}
+
+// cueError converts CUE errors into *ValidationError,
+// doc is used to distinguish list indexes from map keys.
+func cueError(doc interface{}, err error) error {
+	res := &ValidationError{}
+	seen := map[string]bool{}
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		if strings.HasSuffix(format, "errors in empty disjunction:") {
+			// summary of the following errors of disjunction elements
+			continue
+		}
+		v := SchemaViolation{
+			Path:    cuePath(doc, e.Path()),
+			Message: fmt.Sprintf(format, args...),
+		}
+		if key := v.String(); !seen[key] {
+			seen[key] = true
+			res.Violations = append(res.Violations, v)
+		}
+	}
+	if len(res.Violations) == 0 {
+		return errors.Trace(err)
+	}
+	sort.SliceStable(res.Violations, func(i, j int) bool {
+		return Change{Path: res.Violations[i].Path}.PathString() < Change{Path: res.Violations[j].Path}.PathString()
+	})
+	return res
+}
+
+// cuePath converts CUE selectors into path.
+func cuePath(doc interface{}, selectors []string) []interface{} {
+	var path []interface{}
+	for _, sel := range selectors {
+		if s, err := strconv.Unquote(sel); err == nil && strings.HasPrefix(sel, `"`) {
+			sel = s
+		}
+		if l, ok := doc.([]interface{}); ok || doc == nil {
+			if idx, err := strconv.Atoi(sel); err == nil && idx >= 0 && (!ok || idx < len(l)) {
+				path = append(path, idx)
+				if ok {
+					doc = l[idx]
+				}
+				continue
+			}
+		}
+		if m, ok := doc.(map[string]interface{}); ok {
+			doc = m[sel]
+		} else {
+			doc = nil
+		}
+		path = append(path, sel)
+	}
+	return path
+}
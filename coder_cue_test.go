@@ -0,0 +1,107 @@
+package fc
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCUE(t *testing.T) {
+	input, err := os.Open("testdata/cue/app.cue")
+	require.NoError(t, err)
+	defer input.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "cue",
+		Encoder: "yaml",
+		Input:   input,
+		Output:  &out,
+	}))
+	require.Equal(t, `env:
+  DEBUG: false
+  MODE: PRODUCTION
+image: registry.local/billing:1.4.2
+name: billing
+ports:
+- 8080
+- 9090
+version: 1.4.2
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "cue",
+		DecoderArgs: []string{"expr=spec.ports"},
+		Encoder:     "json",
+		Input:       bytes.NewBufferString("spec: ports: [for p in [80, 443] {p + 8000}]"),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `[8080, 8443]`, out.String())
+
+	err = testRecoder.Run(&Config{
+		Decoder: "cue",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("a: int"),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), ".a: incomplete value int")
+}
+
+func TestCUESchema(t *testing.T) {
+	schema, err := testRecoder.LoadCUESchema("testdata/cue/schema.cue")
+	require.NoError(t, err)
+
+	input, err := os.Open("testdata/cue/valid.yml")
+	require.NoError(t, err)
+	defer input.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "yaml",
+		Encoder: "json",
+		Input:   input,
+		Output:  &out,
+		CUE:     schema,
+	}))
+	require.JSONEq(t, `{
+		"name": "billing",
+		"replicas": 1,
+		"log": {"level": "warn", "format": "json"},
+		"ports": [8080]
+	}`, out.String())
+
+	data, _, err := testRecoder.Decode(&Config{
+		Decoder: "yaml",
+		Input: bytes.NewBufferString(`
+name: Billing
+replicas: 0
+ports: [8080, 70000]
+log:
+  format: xml
+extra: true
+`),
+	})
+	require.NoError(t, err)
+
+	_, err = schema.Unify(data)
+	require.IsType(t, &ValidationError{}, err)
+	var res []string
+	for _, v := range err.(*ValidationError).Violations {
+		res = append(res, v.String())
+	}
+	require.Equal(t, []string{
+		`.extra: field not allowed`,
+		`.log.format: conflicting values "json" and "xml"`,
+		`.log.format: conflicting values "text" and "xml"`,
+		`.name: invalid value "Billing" (out of bound =~"^[a-z]+$")`,
+		`.ports[1]: invalid value 70000 (out of bound <65536)`,
+		`.replicas: conflicting values 1 and 0`,
+		`.replicas: invalid value 0 (out of bound >=1)`,
+	}, res)
+
+	_, err = CompileCUESchema("broken.cue", []byte("a: int & string"))
+	require.Contains(t, err.Error(), "invalid cue schema")
+}
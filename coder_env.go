@@ -0,0 +1,328 @@
+package fc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/juju/errors"
+)
+
+// escapedDollar marks escaped '$' in double quoted values until expansion.
+const escapedDollar = "\x00"
+
+var (
+	envKey       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	envPlain     = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+	envReference = regexp.MustCompile(`\$(\{[^}]*\}|[A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// coderEnv decodes .env files and encodes data as environment
+// variables, nested keys are flattened into SCREAMING_SNAKE names.
+type coderEnv struct{}
+
+func (c *coderEnv) Initialize() error {
+	return nil
+}
+
+func (c *coderEnv) Names() []string {
+	return []string{"dotenv", "env"}
+}
+
+// Decode parses KEY=VALUE lines with optional 'export' prefix.
+// Single quoted values are literal, double quoted values support
+// escapes, both can span multiple lines. References ${VAR}, $VAR
+// and ${VAR:-default} in unquoted and double quoted values are
+// expanded from previously defined keys or the environment.
+func (c *coderEnv) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	expand := true
+	for _, arg := range args {
+		switch arg {
+		case "noexpand":
+			expand = false
+		default:
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("dotenv: invalid input argument '%s', supported arguments: 'noexpand'", arg)})
+		}
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "dotenv: cannot read input")
+	}
+
+	res := map[string]interface{}{}
+	lookup := func(name string) (string, bool) {
+		if v, ok := res[name]; ok {
+			return v.(string), true
+		}
+		return os.LookupEnv(name)
+	}
+	p := &dotenvParser{data: strings.Replace(string(data), "\r\n", "\n", -1), line: 1}
+	for p.skipBlank() {
+		line := p.line
+		key, ok := p.key()
+		if !ok || !envKey.MatchString(key) {
+			return nil, nil, errors.Errorf("dotenv: line %d: invalid key '%s'", line, key)
+		}
+		value, quote, err := p.value()
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "dotenv: line %d", line)
+		}
+		if expand && quote != '\'' {
+			value = expandEnv(value, lookup)
+		}
+		res[key] = strings.Replace(value, escapedDollar, "$", -1)
+	}
+	return res, nil, nil
+}
+
+// dotenvParser reads entries of .env file.
+type dotenvParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.data[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips whitespace, empty and comment lines,
+// it returns false at the end of input.
+func (p *dotenvParser) skipBlank() bool {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (p *dotenvParser) skipLine() {
+	for p.pos < len(p.data) && p.next() != '\n' {
+	}
+}
+
+// key reads the key up to '=' and strips 'export' prefix.
+func (p *dotenvParser) key() (string, bool) {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != '=' && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	key := strings.TrimSpace(p.data[start:p.pos])
+	if strings.HasPrefix(key, "export ") || strings.HasPrefix(key, "export\t") {
+		key = strings.TrimSpace(key[len("export"):])
+	}
+	if p.pos == len(p.data) || p.data[p.pos] != '=' {
+		return key, false
+	}
+	p.pos++
+	return key, true
+}
+
+// value reads the value after '=' and returns it with the quote character.
+func (p *dotenvParser) value() (string, byte, error) {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos == len(p.data) {
+		return "", 0, nil
+	}
+
+	quote := p.data[p.pos]
+	if quote != '\'' && quote != '"' {
+		start := p.pos
+		p.skipLine()
+		value := strings.TrimRight(p.data[start:p.pos], "\n")
+		// comment must be preceded by whitespace
+		for i := 1; i < len(value); i++ {
+			if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+				value = value[:i]
+				break
+			}
+		}
+		return strings.TrimSpace(value), 0, nil
+	}
+
+	p.pos++
+	var buf strings.Builder
+	for p.pos < len(p.data) {
+		c := p.next()
+		switch {
+		case c == quote:
+			rest := p.pos
+			p.skipLine()
+			if tail := strings.TrimSpace(p.data[rest:p.pos]); tail != "" && tail[0] != '#' {
+				return "", 0, errors.Errorf("unexpected '%s' after quoted value", tail)
+			}
+			return buf.String(), quote, nil
+		case c == '\\' && quote == '"' && p.pos < len(p.data):
+			switch e := p.next(); e {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'r':
+				buf.WriteByte('\r')
+			case '$':
+				buf.WriteString(escapedDollar)
+			default:
+				buf.WriteByte(e)
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", 0, errors.Errorf("unterminated quoted value")
+}
+
+// expandEnv replaces variable references in s.
+func expandEnv(s string, lookup func(string) (string, bool)) string {
+	return envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.TrimPrefix(ref, "$")
+		var def string
+		if strings.HasPrefix(name, "{") {
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "{"), "}")
+			if i := strings.Index(name, ":-"); i >= 0 {
+				name, def = name[:i], name[i+2:]
+			}
+		}
+		if v, ok := lookup(name); ok && v != "" {
+			return v
+		}
+		return def
+	})
+}
+
+// Encode writes data as KEY=VALUE lines, sorted by key. Values
+// are quoted for POSIX shell, so output can be both evaluated
+// by shell and read as .env file.
+func (c *coderEnv) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	var prefix, export string
+	sep := "_"
+	for _, arg := range args {
+		switch {
+		case arg == "export":
+			export = "export "
+		case strings.HasPrefix(arg, "sep="):
+			sep = strings.TrimPrefix(arg, "sep=")
+		case strings.HasPrefix(arg, "prefix="):
+			prefix = strings.TrimPrefix(arg, "prefix=")
+		case !strings.Contains(arg, "="):
+			prefix = arg
+		default:
+			return errors.Trace(ArgumentError{error: fmt.Sprintf("env: invalid output argument '%s', supported arguments: 'PREFIX', 'prefix=PREFIX', 'sep=SEPARATOR', 'export'", arg)})
+		}
+	}
+
+	root, ok := normalizeValue(in).(map[string]interface{})
+	if !ok {
+		return errors.Errorf("env: cannot encode %s, map is expected", valueKind(in))
+	}
+	vars := map[string]string{}
+	var walk func(name string, v interface{}) error
+	walk = func(name string, v interface{}) error {
+		join := func(key string) string {
+			if name == "" {
+				return prefix + key
+			}
+			return name + sep + key
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, k := range sortedKeys(v) {
+				if err := walk(join(screamingSnake(k)), v[k]); err != nil {
+					return err
+				}
+			}
+			return nil
+		case []interface{}:
+			for i, e := range v {
+				if err := walk(join(fmt.Sprint(i)), e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if !envKey.MatchString(name) || strings.Contains(name, ".") {
+			return errors.Errorf("env: invalid variable name '%s'", name)
+		}
+		if _, ok := vars[name]; ok {
+			return errors.Errorf("env: duplicate variable name '%s'", name)
+		}
+		vars[name] = ""
+		if v != nil {
+			vars[name] = fmt.Sprint(v)
+		}
+		return nil
+	}
+	if err := walk("", root); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := bufio.NewWriter(out)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s%s=%s\n", export, name, quoteShell(vars[name]))
+	}
+	return errors.Annotatef(w.Flush(), "env: cannot write")
+}
+
+// screamingSnake converts key like "imagePullPolicy"
+// or "api-url" into "IMAGE_PULL_POLICY" or "API_URL".
+func screamingSnake(key string) string {
+	runes := []rune(key)
+	var buf strings.Builder
+	underscore := func() {
+		if s := buf.String(); s != "" && !strings.HasSuffix(s, "_") {
+			buf.WriteByte('_')
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) || r > unicode.MaxASCII {
+			underscore()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				underscore()
+			}
+		}
+		buf.WriteRune(unicode.ToUpper(r))
+	}
+	return strings.Trim(buf.String(), "_")
+}
+
+// quoteShell quotes s for POSIX shell, values without
+// special characters are written as is.
+func quoteShell(s string) string {
+	switch {
+	case envPlain.MatchString(s):
+		return s
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,136 @@
+package fc
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDotenv(t *testing.T) {
+	os.Setenv("GOFC_TEST_HOME", "/home/test")
+	defer os.Unsetenv("GOFC_TEST_HOME")
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "dotenv",
+		Encoder: "json",
+		Input: bytes.NewBufferString(`# comment
+export NAME=app # inline comment
+URL=http://host/#anchor
+EMPTY=
+SINGLE='literal ${NAME}'
+DOUBLE="${NAME} says \"hi\"\n"
+ESCAPED="\${NAME}"
+HOME_DIR=${GOFC_TEST_HOME}/app
+DEFAULT=${GOFC_TEST_MISSING:-fallback}
+MULTI="first
+second"
+`),
+		Output: &out,
+	}))
+	require.JSONEq(t, `{
+		"NAME": "app",
+		"URL": "http://host/#anchor",
+		"EMPTY": "",
+		"SINGLE": "literal ${NAME}",
+		"DOUBLE": "app says \"hi\"\n",
+		"ESCAPED": "${NAME}",
+		"HOME_DIR": "/home/test/app",
+		"DEFAULT": "fallback",
+		"MULTI": "first\nsecond"
+	}`, out.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder: "dotenv",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("A=1\nB='unterminated\n"),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "dotenv: line 2: unterminated quoted value")
+}
+
+const testEnvInput = `{
+	"name": "app",
+	"imagePullPolicy": "Always",
+	"db": {"host": "localhost", "port": 5432, "password": "it's $secret"},
+	"hosts": ["a", "b c"],
+	"debug": false,
+	"empty": null
+}`
+
+func TestEnv(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "env",
+		EncoderArgs: []string{"APP_"},
+		Input:       bytes.NewBufferString(testEnvInput),
+		Output:      &out,
+	}))
+	require.Equal(t, `APP_DB_HOST=localhost
+APP_DB_PASSWORD="it's \$secret"
+APP_DB_PORT=5432
+APP_DEBUG=false
+APP_EMPTY=
+APP_HOSTS_0=a
+APP_HOSTS_1='b c'
+APP_IMAGE_PULL_POLICY=Always
+APP_NAME=app
+`, out.String())
+
+	// output is read back by dotenv decoder
+	var out2 bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "dotenv",
+		Encoder: "json",
+		Input:   bytes.NewBufferString(out.String()),
+		Output:  &out2,
+	}))
+	require.JSONEq(t, `{
+		"APP_DB_HOST": "localhost",
+		"APP_DB_PASSWORD": "it's $secret",
+		"APP_DB_PORT": "5432",
+		"APP_DEBUG": "false",
+		"APP_EMPTY": "",
+		"APP_HOSTS_0": "a",
+		"APP_HOSTS_1": "b c",
+		"APP_IMAGE_PULL_POLICY": "Always",
+		"APP_NAME": "app"
+	}`, out2.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "env",
+		EncoderArgs: []string{"prefix=APP__", "sep=__", "export"},
+		Input:       bytes.NewBufferString(`{"db": {"host": "it's 'quoted'\n"}}`),
+		Output:      &out,
+	}))
+	require.Equal(t, "export APP__DB__HOST=\"it's 'quoted'\n\"\n", out.String())
+
+	// output is evaluated by shell
+	if sh, err := exec.LookPath("sh"); err == nil {
+		out.Reset()
+		require.NoError(t, testRecoder.Run(&Config{
+			Decoder:     "json",
+			Encoder:     "env",
+			EncoderArgs: []string{"export"},
+			Input:       bytes.NewBufferString(`{"a": "it's \"$HOME\" ` + "`x`" + ` \\n", "b": "line\nbreak"}`),
+			Output:      &out,
+		}))
+		res, err := exec.Command(sh, "-c", out.String()+`printf '%s|%s' "$A" "$B"`).Output()
+		require.NoError(t, err)
+		require.Equal(t, "it's \"$HOME\" `x` \\n|line\nbreak", string(res))
+	}
+
+	err := testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "env",
+		Input:   bytes.NewBufferString(`{"a_b": 1, "aB": 2}`),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "env: duplicate variable name 'A_B'")
+}
@@ -0,0 +1,91 @@
+package fc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+)
+
+// coderFrontMatter splits Markdown or HTML document into front matter,
+// which is decoded as data, and the body, which is returned as metadata.
+type coderFrontMatter struct{}
+
+func (c *coderFrontMatter) Initialize() error {
+	return nil
+}
+
+func (c *coderFrontMatter) Names() []string {
+	return []string{"frontmatter"}
+}
+
+// Decode detects front matter format by the first line: '---' for YAML,
+// '+++' for TOML and '{' for JSON object. Documents without front
+// matter are decoded into empty map.
+func (c *coderFrontMatter) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("frontmatter: invalid input argument '%s', no arguments are supported", args[0])})
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "frontmatter: cannot read input")
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var decoder Decoder
+	var format string
+	var matter, body []byte
+	first, rest := splitLine(data)
+	switch delim := string(bytes.TrimRight(first, " \t\r\n")); {
+	case delim == "---" || delim == "+++":
+		decoder, format = &coderYAML{}, "yaml"
+		if delim == "+++" {
+			decoder, format = &coderTOML{}, "toml"
+		}
+		for start := rest; ; {
+			if len(rest) == 0 {
+				return nil, nil, errors.Errorf("frontmatter: closing '%s' is not found", delim)
+			}
+			var line []byte
+			line, rest = splitLine(rest)
+			if string(bytes.TrimRight(line, " \t\r\n")) == delim {
+				matter = start[:len(start)-len(rest)-len(line)]
+				break
+			}
+		}
+		body = rest
+	case bytes.HasPrefix(bytes.TrimSpace(first), []byte("{")):
+		decoder, format = &coderJSON{}, "json"
+		var raw json.RawMessage
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if err = dec.Decode(&raw); err != nil {
+			return nil, nil, errors.Annotatef(err, "frontmatter: cannot decode json front matter")
+		}
+		matter = raw
+		// rest of the line after closing brace belongs to front matter
+		_, body = splitLine(data[dec.InputOffset():])
+	default:
+		return map[string]interface{}{}, string(data), nil
+	}
+
+	res, _, err := decoder.Decode(bytes.NewReader(matter), nil)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "frontmatter: cannot decode %s front matter", format)
+	}
+	if res == nil {
+		res = map[string]interface{}{}
+	}
+	return res, string(body), nil
+}
+
+// splitLine returns the first line of data, including
+// the line break, and the rest of data.
+func splitLine(data []byte) ([]byte, []byte) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[:i+1], data[i+1:]
+	}
+	return data, nil
+}
@@ -0,0 +1,48 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontMatter(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		data  interface{}
+		body  string
+	}{
+		{"---\ntitle: Hello\ntags: [a, b]\n---\n# Hello\n", map[string]interface{}{"title": "Hello", "tags": []interface{}{"a", "b"}}, "# Hello\n"},
+		{"+++\r\ntitle = \"Hello\"\r\n+++\r\nbody", map[string]interface{}{"title": "Hello"}, "body"},
+		{"{\n  \"title\": \"Hello\"\n}\n<p>body</p>\n", map[string]interface{}{"title": "Hello"}, "<p>body</p>\n"},
+		{"---\n# no fields\n---\nbody", map[string]interface{}{}, "body"},
+		{"# Hello\n---\n", map[string]interface{}{}, "# Hello\n---\n"},
+	} {
+		data, metadata, err := testRecoder.Decode(&Config{
+			Decoder: "frontmatter",
+			Input:   bytes.NewBufferString(tc.input),
+		})
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.data, data, tc.input)
+		require.Equal(t, tc.body, metadata, tc.input)
+	}
+
+	_, _, err := testRecoder.Decode(&Config{
+		Decoder: "frontmatter",
+		Input:   bytes.NewBufferString("---\ntitle: Hello\n"),
+	})
+	require.Contains(t, err.Error(), "closing '---' is not found")
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "null",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"testdata/frontmatter/index.tpl"},
+		Output:      &out,
+	}))
+	require.Equal(t, `
+- [API](api.md): # API
+- [Installation](install.md): # Installation
+`, out.String())
+}
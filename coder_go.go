@@ -0,0 +1,307 @@
+package fc
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/juju/errors"
+)
+
+// goInitialisms are name parts, which are written in upper case.
+var goInitialisms = map[string]bool{
+	"API": true, "ARN": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "VPC": true, "XML": true, "YAML": true,
+}
+
+var goTagNames = []string{"json", "yaml", "toml"}
+
+// coderGo generates Go type definitions from sample documents.
+type coderGo struct{}
+
+func (c *coderGo) Initialize() error {
+	return nil
+}
+
+func (c *coderGo) Names() []string {
+	return []string{"go"}
+}
+
+func (c *coderGo) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	gen := &goGenerator{
+		pkg:   "main",
+		root:  "Config",
+		tags:  goTagNames,
+		names: make(map[string]bool),
+		types: make(map[string]string),
+		defs:  make(map[string]string),
+		refs:  make(map[string][]string),
+	}
+	var multi bool
+	for _, arg := range args {
+		switch {
+		case arg == "multi":
+			multi = true
+		case strings.HasPrefix(arg, "package="):
+			gen.pkg = strings.TrimPrefix(arg, "package=")
+		case strings.HasPrefix(arg, "type="):
+			gen.root = strings.TrimPrefix(arg, "type=")
+		case strings.HasPrefix(arg, "tags="):
+			gen.tags = nil
+			for _, tag := range strings.Split(strings.TrimPrefix(arg, "tags="), ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					gen.tags = append(gen.tags, tag)
+				}
+			}
+		default:
+			return errors.Trace(ArgumentError{error: fmt.Sprintf("go: invalid output argument '%s', supported arguments: 'package=NAME', 'type=NAME', 'tags=json,yaml,toml', 'multi'", arg)})
+		}
+	}
+	if !isGoIdent(gen.pkg) || !isGoIdent(gen.root) {
+		return errors.Trace(ArgumentError{error: fmt.Sprintf("go: invalid package '%s' or type name '%s'", gen.pkg, gen.root)})
+	}
+
+	docs := []interface{}{normalizeValue(in)}
+	if multi {
+		var ok bool
+		if docs, ok = docs[0].([]interface{}); !ok {
+			return errors.Errorf("go: list of documents is expected with 'multi' argument")
+		}
+	}
+	node := &schemaNode{}
+	for _, doc := range docs {
+		node.observe(doc)
+	}
+
+	src, err := gen.generate(node)
+	if err != nil {
+		return errors.Annotatef(err, "go: cannot format generated code")
+	}
+	_, err = out.Write(src)
+	return errors.Annotatef(err, "go: cannot write")
+}
+
+// goGenerator converts observed document shape into Go types.
+type goGenerator struct {
+	pkg  string
+	root string
+	tags []string
+
+	// names are used type names, types maps
+	// struct body to the name of the struct
+	names map[string]bool
+	types map[string]string
+
+	// defs are type definitions by name, refs
+	// are names of types used by the definition
+	defs map[string]string
+	refs map[string][]string
+}
+
+func (g *goGenerator) generate(node *schemaNode) ([]byte, error) {
+	g.names[g.root] = true
+	if isGoObject(node) {
+		body, refs := g.structBody(node, g.root)
+		g.types[body] = g.root
+		g.define(g.root, body, refs)
+	} else {
+		var refs []string
+		g.define(g.root, g.goType(node, g.root, "", &refs), refs)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gofc. DO NOT EDIT.\n\npackage %s\n", g.pkg)
+	g.write(&buf, g.root, make(map[string]bool))
+	return format.Source(buf.Bytes())
+}
+
+func (g *goGenerator) define(name, typ string, refs []string) {
+	g.defs[name] = fmt.Sprintf("type %s %s", name, typ)
+	g.refs[name] = refs
+}
+
+// write writes definition of type name followed by
+// definitions of types it uses, in order of fields.
+func (g *goGenerator) write(buf *bytes.Buffer, name string, written map[string]bool) {
+	if written[name] {
+		return
+	}
+	written[name] = true
+	fmt.Fprintf(buf, "\n%s\n", g.defs[name])
+	for _, ref := range g.refs[name] {
+		g.write(buf, ref, written)
+	}
+}
+
+// goType returns Go type of node, nested structs are named
+// after the key, parent is used to resolve name conflicts.
+func (g *goGenerator) goType(node *schemaNode, key, parent string, refs *[]string) string {
+	var types []string
+	for t := range node.types {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 2 && node.types["integer"] && node.types["number"] {
+		types = []string{"number"}
+	}
+	if len(types) != 1 {
+		return "interface{}"
+	}
+
+	var typ string
+	switch types[0] {
+	case "boolean":
+		typ = "bool"
+	case "integer":
+		typ = "int"
+	case "number":
+		typ = "float64"
+	case "string":
+		typ = "string"
+	case "array":
+		if node.items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(node.items, singular(key), parent, refs)
+	case "object":
+		if !isGoObject(node) {
+			return "map[string]interface{}"
+		}
+		typ = g.structType(node, key, parent)
+		*refs = append(*refs, typ)
+	}
+	if node.types["null"] {
+		return "*" + typ
+	}
+	return typ
+}
+
+// structType defines struct for node and returns its name.
+// Structs with identical fields are defined only once.
+func (g *goGenerator) structType(node *schemaNode, key, parent string) string {
+	name := goName(key)
+	if name == "" {
+		name = "Item"
+	}
+	body, refs := g.structBody(node, name)
+	if existing, ok := g.types[body]; ok {
+		return existing
+	}
+	if g.names[name] && parent != "" {
+		name = parent + name
+	}
+	for i, base := 2, name; g.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.names[name] = true
+	g.types[body] = name
+	g.define(name, body, refs)
+	return name
+}
+
+// structBody returns struct definition of node
+// and names of nested types used by its fields.
+func (g *goGenerator) structBody(node *schemaNode, name string) (string, []string) {
+	var refs []string
+	keys := make([]string, 0, len(node.properties))
+	for k := range node.properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	buf.WriteString("struct {\n")
+	fields := make(map[string]bool)
+	for _, k := range keys {
+		p := node.properties[k]
+		field := goName(k)
+		if field == "" {
+			field = "Field"
+		}
+		for i, base := 2, field; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", base, i)
+		}
+		fields[field] = true
+
+		var tags []string
+		for _, tag := range g.tags {
+			if p.seen < node.objects {
+				tags = append(tags, fmt.Sprintf(`%s:"%s,omitempty"`, tag, k))
+			} else {
+				tags = append(tags, fmt.Sprintf(`%s:"%s"`, tag, k))
+			}
+		}
+		fmt.Fprintf(&buf, "\t%s %s", field, g.goType(p, k, name, &refs))
+		if len(tags) > 0 {
+			fmt.Fprintf(&buf, " `%s`", strings.Join(tags, " "))
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.String(), refs
+}
+
+// isGoObject reports whether node is an object with keys,
+// which can be represented as struct.
+func isGoObject(node *schemaNode) bool {
+	return node.types["object"] && len(node.properties) > 0
+}
+
+// goName converts key into exported Go identifier,
+// e.g. "api_url" into "APIURL".
+func goName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var buf strings.Builder
+	for _, part := range parts {
+		// split camelCase parts, e.g. "imagePullPolicy"
+		var start int
+		runes := []rune(part)
+		for i := 1; i <= len(runes); i++ {
+			if i == len(runes) || unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+				word := string(runes[start:i])
+				if upper := strings.ToUpper(word); goInitialisms[upper] {
+					buf.WriteString(upper)
+				} else {
+					w := []rune(word)
+					buf.WriteString(string(unicode.ToUpper(w[0])) + string(w[1:]))
+				}
+				start = i
+			}
+		}
+	}
+	name := buf.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// singular returns naive singular form of
+// the key, which is used to name list items.
+func singular(key string) string {
+	switch {
+	case strings.HasSuffix(key, "ies") && len(key) > 3:
+		return key[:len(key)-3] + "y"
+	case strings.HasSuffix(key, "s") && !strings.HasSuffix(key, "ss") && len(key) > 1:
+		return key[:len(key)-1]
+	}
+	return key + "Item"
+}
+
+func isGoIdent(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,59 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "go",
+		EncoderArgs: []string{"package=config", "type=Service", "tags=json,yaml"},
+		Input: bytes.NewBufferString(`
+name: web
+api_url: http://localhost
+primary: {host: db1, port: 5432}
+replica: {host: db2, port: 5432}
+containers:
+  - name: app
+    ports: [80]
+  - name: sidecar
+    cpu: 0.5
+`),
+		Output: &out,
+	}))
+	require.Equal(t, "// Code generated by gofc. DO NOT EDIT.\n\npackage config\n\n"+
+		"type Service struct {\n"+
+		"\tAPIURL     string      `json:\"api_url\" yaml:\"api_url\"`\n"+
+		"\tContainers []Container `json:\"containers\" yaml:\"containers\"`\n"+
+		"\tName       string      `json:\"name\" yaml:\"name\"`\n"+
+		"\tPrimary    Primary     `json:\"primary\" yaml:\"primary\"`\n"+
+		"\tReplica    Primary     `json:\"replica\" yaml:\"replica\"`\n"+
+		"}\n\n"+
+		"type Container struct {\n"+
+		"\tCPU   float64 `json:\"cpu,omitempty\" yaml:\"cpu,omitempty\"`\n"+
+		"\tName  string  `json:\"name\" yaml:\"name\"`\n"+
+		"\tPorts []int   `json:\"ports,omitempty\" yaml:\"ports,omitempty\"`\n"+
+		"}\n\n"+
+		"type Primary struct {\n"+
+		"\tHost string `json:\"host\" yaml:\"host\"`\n"+
+		"\tPort int    `json:\"port\" yaml:\"port\"`\n"+
+		"}\n", out.String())
+}
+
+func TestGoName(t *testing.T) {
+	for key, name := range map[string]string{
+		"imagePullPolicy": "ImagePullPolicy",
+		"api_url":         "APIURL",
+		"user-id":         "UserID",
+		"HTTPPort":        "HTTPPort",
+		"3d":              "X3d",
+		"_":               "",
+	} {
+		require.Equal(t, name, goName(key), key)
+	}
+}
@@ -27,7 +27,7 @@ second = {
 
 func TestHCL(t *testing.T) {
 	var out1 bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "h",
 		Encoder: "j",
 		Input:   bytes.NewBufferString(testInputHCL),
@@ -35,7 +35,7 @@ func TestHCL(t *testing.T) {
 	}))
 	require.JSONEq(t, `{"inputs":{"str": "asd", "map": {"key1":"value1"}, "ignored":null}, "second": {"list": [1, "a"]}}`, out1.String())
 	var out2 bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "j",
 		Encoder: "h",
 		Input:   &out1,
@@ -47,7 +47,7 @@ func TestHCL(t *testing.T) {
 
 func TestHCLMetadata(t *testing.T) {
 	var out1 bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder:     "h",
 		Encoder:     "tpl",
 		EncoderArgs: []string{"./testdata/metadata.tpl"},
@@ -0,0 +1,863 @@
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// hoconMaxIncludeDepth limits nested includes, e.g. files including each other.
+const hoconMaxIncludeDepth = 32
+
+var hoconNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coderHOCON decodes HOCON (Human-Optimized Config Object Notation).
+// Includes are resolved relative to the decoded file, substitutions
+// fall back to environment variables.
+type coderHOCON struct {
+	conv *Recoder
+}
+
+func (c *coderHOCON) Initialize() error {
+	return nil
+}
+
+func (c *coderHOCON) Names() []string {
+	return []string{"hocon"}
+}
+
+func (c *coderHOCON) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	return c.DecodeContext(context.Background(), in, args)
+}
+
+// DecodeContext is implemented to receive the input as is, includes
+// are resolved relative to the input file, if it is a named file.
+func (c *coderHOCON) DecodeContext(ctx context.Context, in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("HOCON: invalid input argument '%s', no arguments expected", args[0])})
+	}
+	data, err := ioutil.ReadAll(&ctxReader{ctx: ctx, r: in})
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "HOCON: cannot read input")
+	}
+	var dir string
+	if name := inputFileName(in); name != "" {
+		dir = filepath.Dir(name)
+	}
+
+	p := &hoconParser{conv: c.conv, data: strings.TrimPrefix(string(data), "\ufeff"), line: 1, dir: dir}
+	root, err := p.parseRoot(nil)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	r := &hoconResolver{root: root, resolved: map[hoconNode]interface{}{}, resolving: map[hoconNode]bool{}}
+	res, err := r.resolve(root)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "HOCON")
+	}
+	return res, nil, nil
+}
+
+// hoconNode is parsed, but not yet resolved value.
+type hoconNode interface{}
+
+type hoconObject struct {
+	keys   []string
+	fields map[string]hoconNode
+}
+
+func newHOCONObject() *hoconObject {
+	return &hoconObject{fields: map[string]hoconNode{}}
+}
+
+func (o *hoconObject) set(key string, v hoconNode) {
+	if _, ok := o.fields[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.fields[key] = v
+}
+
+type hoconArray struct {
+	items []hoconNode
+}
+
+type hoconScalar struct {
+	value interface{}
+	// raw is the source text, used for concatenation
+	raw string
+	// space is set for whitespace between concatenated values
+	space bool
+}
+
+type hoconSubst struct {
+	path     []string
+	optional bool
+	// prefix is the path of include location, substitutions
+	// of included files are looked up relative to it first
+	prefix []string
+}
+
+type hoconConcat struct {
+	parts []hoconNode
+}
+
+// hoconUndefined is the result of undefined optional substitution.
+var hoconUndefined = &struct{}{}
+
+// hoconParser parses HOCON source into hoconNodes.
+type hoconParser struct {
+	conv  *Recoder
+	data  string
+	pos   int
+	line  int
+	dir   string
+	depth int
+}
+
+func (p *hoconParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("HOCON: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *hoconParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *hoconParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *hoconParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.data[p.pos:], s)
+}
+
+func (p *hoconParser) advance(n int) {
+	for i := 0; i < n && !p.eof(); i++ {
+		if p.data[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skipSpace skips spaces and tabs, and also newlines
+// and comments if all is set.
+func (p *hoconParser) skipSpace(all bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.advance(1)
+		case all && c == '\n':
+			p.advance(1)
+		case all && p.atComment():
+			for !p.eof() && p.peek() != '\n' {
+				p.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// skipSeparator skips a single comma separating
+// object fields or array elements, if present.
+func (p *hoconParser) skipSeparator() {
+	p.skipSpace(true)
+	if p.peek() == ',' {
+		p.advance(1)
+	}
+}
+
+func (p *hoconParser) atComment() bool {
+	return p.peek() == '#' || p.hasPrefix("//")
+}
+
+// atValueEnd reports whether the current value ends at current position.
+func (p *hoconParser) atValueEnd() bool {
+	switch p.peek() {
+	case 0, '\n', ',', '}', ']':
+		return true
+	}
+	return p.atComment()
+}
+
+// atInclude reports whether include statement starts at current position.
+func (p *hoconParser) atInclude() bool {
+	if !p.hasPrefix("include") {
+		return false
+	}
+	rest := strings.TrimLeft(p.data[p.pos+len("include"):], " \t")
+	for _, prefix := range []string{`"`, "file(", "required(", "url(", "classpath("} {
+		if strings.HasPrefix(rest, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHOCONUnquoted(c byte) bool {
+	return !strings.ContainsRune("$\"{}[]:=,+#`^?!@*&\\ \t\r\n", rune(c))
+}
+
+// parseRoot parses the whole document, path is the
+// location of included document in the including one.
+func (p *hoconParser) parseRoot(path []string) (*hoconObject, error) {
+	p.skipSpace(true)
+	obj := newHOCONObject()
+	if p.peek() == '{' {
+		p.advance(1)
+		if err := p.parseFields(obj, path, '}'); err != nil {
+			return nil, err
+		}
+		p.skipSpace(true)
+	} else if p.peek() == '[' {
+		return nil, p.errorf("root array is not supported, object is expected")
+	} else if err := p.parseFields(obj, path, 0); err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+	return obj, nil
+}
+
+// parseFields parses object fields until closing character or end of input.
+func (p *hoconParser) parseFields(obj *hoconObject, path []string, closing byte) error {
+	for {
+		p.skipSpace(true)
+		switch {
+		case p.peek() == ',':
+			return p.errorf("unexpected ',', expecting key")
+		case p.eof() && closing == 0:
+			return nil
+		case p.eof():
+			return p.errorf("unterminated object, expecting '%c'", closing)
+		case p.peek() == closing:
+			p.advance(1)
+			return nil
+		case p.atInclude():
+			p.advance(len("include"))
+			if err := p.parseInclude(obj, path); err != nil {
+				return err
+			}
+			p.skipSeparator()
+			continue
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		p.skipSpace(false)
+		var appendValue bool
+		switch {
+		case p.peek() == '{':
+		case p.peek() == '=' || p.peek() == ':':
+			p.advance(1)
+		case p.hasPrefix("+="):
+			p.advance(2)
+			appendValue = true
+		default:
+			return p.errorf("expecting '=', ':' or '{' after key '%s'", strings.Join(key, "."))
+		}
+		p.skipSpace(false)
+		fieldPath := append(append([]string{}, path...), key...)
+		value, err := p.parseValue(fieldPath)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			return p.errorf("missing value of key '%s'", strings.Join(key, "."))
+		}
+		p.assign(obj, path, key, value, appendValue)
+		p.skipSpace(false)
+		if !p.atValueEnd() {
+			return p.errorf("unexpected '%c' after value of '%s'", p.peek(), strings.Join(key, "."))
+		}
+		p.skipSeparator()
+	}
+}
+
+// parseKey parses path expression, e.g. a."b.c".d
+func (p *hoconParser) parseKey() ([]string, error) {
+	var key []string
+	var part strings.Builder
+	var quoted bool
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '"':
+			s, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			part.WriteString(s)
+			quoted = true
+			continue
+		case c == '.':
+			key = append(key, part.String())
+			part.Reset()
+			quoted = false
+		case isHOCONUnquoted(c) && !p.hasPrefix("//"):
+			part.WriteByte(c)
+		default:
+			if part.Len() == 0 && !quoted {
+				return nil, p.errorf("expecting key, got '%c'", c)
+			}
+			return append(key, part.String()), nil
+		}
+		p.advance(1)
+	}
+	return nil, p.errorf("unexpected end of input after key")
+}
+
+// parseValue parses value, which can be concatenation of several
+// values on the same line. It returns nil, if there is no value.
+func (p *hoconParser) parseValue(path []string) (hoconNode, error) {
+	var parts []hoconNode
+	for !p.atValueEnd() {
+		var part hoconNode
+		var err error
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			start := p.pos
+			p.skipSpace(false)
+			part = &hoconScalar{value: p.data[start:p.pos], raw: p.data[start:p.pos], space: true}
+		case c == '"':
+			var s string
+			if p.hasPrefix(`"""`) {
+				s, err = p.parseTripleQuoted()
+			} else {
+				s, err = p.parseQuoted()
+			}
+			part = &hoconScalar{value: s, raw: s}
+		case c == '{':
+			p.advance(1)
+			obj := newHOCONObject()
+			err = p.parseFields(obj, path, '}')
+			part = obj
+		case c == '[':
+			part, err = p.parseArray(path)
+		case p.hasPrefix("${"):
+			part, err = p.parseSubst()
+		case isHOCONUnquoted(c):
+			start := p.pos
+			for !p.eof() && isHOCONUnquoted(p.peek()) && !p.hasPrefix("//") {
+				p.advance(1)
+			}
+			part = hoconToken(p.data[start:p.pos])
+		default:
+			return nil, p.errorf("unexpected '%c'", c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	// whitespace is significant only between values
+	for len(parts) > 0 && isHOCONSpace(parts[0]) {
+		parts = parts[1:]
+	}
+	for len(parts) > 0 && isHOCONSpace(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	switch len(parts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return parts[0], nil
+	}
+	return &hoconConcat{parts: parts}, nil
+}
+
+func isHOCONSpace(n hoconNode) bool {
+	s, ok := n.(*hoconScalar)
+	return ok && s.space
+}
+
+// hoconToken converts unquoted token into scalar.
+func hoconToken(s string) *hoconScalar {
+	switch s {
+	case "true":
+		return &hoconScalar{value: true, raw: s}
+	case "false":
+		return &hoconScalar{value: false, raw: s}
+	case "null":
+		return &hoconScalar{value: nil, raw: s}
+	}
+	if hoconNumber.MatchString(s) {
+		if i, err := strconv.Atoi(s); err == nil {
+			return &hoconScalar{value: i, raw: s}
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return &hoconScalar{value: f, raw: s}
+		}
+	}
+	return &hoconScalar{value: s, raw: s}
+}
+
+func (p *hoconParser) parseArray(path []string) (hoconNode, error) {
+	p.advance(1)
+	arr := &hoconArray{}
+	for {
+		p.skipSpace(true)
+		switch {
+		case p.peek() == ',':
+			return nil, p.errorf("unexpected ',' in array")
+		case p.eof():
+			return nil, p.errorf("unterminated array, expecting ']'")
+		case p.peek() == ']':
+			p.advance(1)
+			return arr, nil
+		}
+		v, err := p.parseValue(path)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, p.errorf("unexpected '%c' in array", p.peek())
+		}
+		arr.items = append(arr.items, v)
+		p.skipSeparator()
+	}
+}
+
+func (p *hoconParser) parseQuoted() (string, error) {
+	start := p.pos
+	p.advance(1)
+	for !p.eof() {
+		switch p.peek() {
+		case '\\':
+			p.advance(2)
+			continue
+		case '\n':
+			return "", p.errorf("unterminated quoted string")
+		case '"':
+			p.advance(1)
+			var s string
+			if err := json.Unmarshal([]byte(p.data[start:p.pos]), &s); err != nil {
+				return "", p.errorf("invalid quoted string %s", p.data[start:p.pos])
+			}
+			return s, nil
+		}
+		p.advance(1)
+	}
+	return "", p.errorf("unterminated quoted string")
+}
+
+func (p *hoconParser) parseTripleQuoted() (string, error) {
+	p.advance(3)
+	end := strings.Index(p.data[p.pos:], `"""`)
+	if end < 0 {
+		return "", p.errorf(`unterminated """ string`)
+	}
+	// extra quotes before the closing ones belong to the string
+	for p.pos+end+3 < len(p.data) && p.data[p.pos+end+3] == '"' {
+		end++
+	}
+	s := p.data[p.pos : p.pos+end]
+	p.advance(end + 3)
+	return s, nil
+}
+
+func (p *hoconParser) parseSubst() (hoconNode, error) {
+	p.advance(2)
+	subst := &hoconSubst{}
+	if p.peek() == '?' {
+		subst.optional = true
+		p.advance(1)
+	}
+	p.skipSpace(false)
+	key, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if p.peek() != '}' {
+		return nil, p.errorf("expecting '}' after substitution '%s'", strings.Join(key, "."))
+	}
+	p.advance(1)
+	subst.path = key
+	return subst, nil
+}
+
+// parseInclude parses include statement and merges included
+// document into obj. Missing files are ignored unless required.
+func (p *hoconParser) parseInclude(obj *hoconObject, path []string) error {
+	p.skipSpace(false)
+	var required bool
+	var closing int
+	if p.hasPrefix("required(") {
+		required = true
+		closing++
+		p.advance(len("required("))
+	}
+	if p.hasPrefix("file(") {
+		closing++
+		p.advance(len("file("))
+	} else if p.hasPrefix("url(") || p.hasPrefix("classpath(") {
+		return p.errorf("only file includes are supported")
+	}
+	if p.peek() != '"' {
+		return p.errorf("expecting quoted file name after include")
+	}
+	name, err := p.parseQuoted()
+	if err != nil {
+		return err
+	}
+	for ; closing > 0; closing-- {
+		if p.peek() != ')' {
+			return p.errorf("expecting ')' in include")
+		}
+		p.advance(1)
+	}
+
+	if p.depth >= hoconMaxIncludeDepth {
+		return p.errorf("too many nested includes")
+	}
+	if !filepath.IsAbs(name) && p.dir != "" {
+		name = filepath.Join(p.dir, name)
+	}
+	names := []string{name}
+	if filepath.Ext(name) == "" {
+		names = append(names, name+".conf", name+".json")
+	}
+	var data []byte
+	for _, n := range names {
+		if data, err = p.conv.readFile(n); err == nil {
+			name = n
+			break
+		}
+	}
+	if err != nil {
+		if !required && os.IsNotExist(errors.Cause(err)) {
+			return nil
+		}
+		return p.errorf("cannot include '%s': %s", name, err)
+	}
+	p.conv.fileAccessed(name)
+
+	included := &hoconParser{conv: p.conv, data: string(data), line: 1, dir: filepath.Dir(name), depth: p.depth + 1}
+	inc, err := included.parseRoot(path)
+	if err != nil {
+		return errors.Annotatef(err, "cannot include '%s'", name)
+	}
+	for _, k := range inc.keys {
+		p.assign(obj, path, []string{k}, prefixSubst(inc.fields[k], path), false)
+	}
+	return nil
+}
+
+// prefixSubst sets include location of substitutions in n.
+func prefixSubst(n hoconNode, prefix []string) hoconNode {
+	if len(prefix) == 0 {
+		return n
+	}
+	switch v := n.(type) {
+	case *hoconSubst:
+		if v.prefix == nil {
+			v.prefix = prefix
+		}
+	case *hoconObject:
+		for _, e := range v.fields {
+			prefixSubst(e, prefix)
+		}
+	case *hoconArray:
+		for _, e := range v.items {
+			prefixSubst(e, prefix)
+		}
+	case *hoconConcat:
+		for _, e := range v.parts {
+			prefixSubst(e, prefix)
+		}
+	}
+	return n
+}
+
+// assign sets value of key path in obj, which is located at path.
+// Objects are merged, other values replace the previous ones.
+func (p *hoconParser) assign(obj *hoconObject, path, key []string, value hoconNode, appendValue bool) {
+	path = append([]string{}, path...)
+	for _, k := range key[:len(key)-1] {
+		path = append(path, k)
+		switch prev := obj.fields[k].(type) {
+		case *hoconObject:
+			obj = prev
+		case *hoconSubst, *hoconConcat:
+			next := newHOCONObject()
+			obj.set(k, &hoconConcat{parts: []hoconNode{prev, next}})
+			obj = next
+		default:
+			next := newHOCONObject()
+			obj.set(k, next)
+			obj = next
+		}
+	}
+	k := key[len(key)-1]
+	path = append(path, k)
+	prev, exists := obj.fields[k]
+
+	switch {
+	case appendValue && exists:
+		value = &hoconConcat{parts: []hoconNode{prev, &hoconArray{items: []hoconNode{value}}}}
+	case appendValue:
+		value = &hoconArray{items: []hoconNode{value}}
+	default:
+		// self-reference, e.g. path = ${path}":/bin", is the previous value
+		value = replaceSelfRef(value, path, prev, exists)
+	}
+
+	if v, ok := value.(*hoconObject); ok && exists {
+		switch prev := prev.(type) {
+		case *hoconObject:
+			mergeHOCONObjects(prev, v)
+			return
+		case *hoconSubst, *hoconConcat:
+			value = &hoconConcat{parts: []hoconNode{prev, v}}
+		}
+	}
+	obj.set(k, value)
+}
+
+func replaceSelfRef(n hoconNode, path []string, prev hoconNode, exists bool) hoconNode {
+	switch v := n.(type) {
+	case *hoconSubst:
+		if strings.Join(v.path, ".") == strings.Join(path, ".") {
+			if exists {
+				return prev
+			}
+			if v.optional {
+				return &hoconScalar{value: hoconUndefined}
+			}
+		}
+	case *hoconConcat:
+		for i, e := range v.parts {
+			v.parts[i] = replaceSelfRef(e, path, prev, exists)
+		}
+	}
+	return n
+}
+
+func mergeHOCONObjects(dst, src *hoconObject) {
+	for _, k := range src.keys {
+		d, dok := dst.fields[k].(*hoconObject)
+		s, sok := src.fields[k].(*hoconObject)
+		if dok && sok {
+			mergeHOCONObjects(d, s)
+		} else {
+			dst.set(k, src.fields[k])
+		}
+	}
+}
+
+// hoconResolver resolves substitutions and concatenations.
+type hoconResolver struct {
+	root      *hoconObject
+	resolved  map[hoconNode]interface{}
+	resolving map[hoconNode]bool
+}
+
+func (r *hoconResolver) resolve(n hoconNode) (interface{}, error) {
+	if v, ok := r.resolved[n]; ok {
+		return v, nil
+	}
+	if r.resolving[n] {
+		if s, ok := n.(*hoconSubst); ok {
+			return nil, errors.Errorf("cycle in substitution ${%s}", strings.Join(s.path, "."))
+		}
+		return nil, errors.Errorf("cycle in substitutions")
+	}
+	r.resolving[n] = true
+	defer delete(r.resolving, n)
+
+	var res interface{}
+	var err error
+	switch v := n.(type) {
+	case *hoconScalar:
+		res = v.value
+	case *hoconObject:
+		m := make(map[string]interface{}, len(v.fields))
+		for _, k := range v.keys {
+			e, err := r.resolve(v.fields[k])
+			if err != nil {
+				return nil, err
+			}
+			if e != hoconUndefined {
+				m[k] = e
+			}
+		}
+		res = m
+	case *hoconArray:
+		l := make([]interface{}, 0, len(v.items))
+		for _, item := range v.items {
+			e, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			if e != hoconUndefined {
+				l = append(l, e)
+			}
+		}
+		res = l
+	case *hoconSubst:
+		res, err = r.resolveSubst(v)
+	case *hoconConcat:
+		res, err = r.resolveConcat(v)
+	default:
+		err = errors.Errorf("unexpected node %T", n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[n] = res
+	return res, nil
+}
+
+func (r *hoconResolver) resolveSubst(s *hoconSubst) (interface{}, error) {
+	paths := [][]string{s.path}
+	if len(s.prefix) > 0 {
+		paths = [][]string{append(append([]string{}, s.prefix...), s.path...), s.path}
+	}
+	for _, path := range paths {
+		v, ok, err := r.lookup(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return v, nil
+		}
+	}
+	if v, ok := os.LookupEnv(strings.Join(s.path, ".")); ok {
+		return v, nil
+	}
+	if s.optional {
+		return hoconUndefined, nil
+	}
+	return nil, errors.Errorf("substitution ${%s} is not defined", strings.Join(s.path, "."))
+}
+
+// lookup returns resolved value at path of the root object.
+func (r *hoconResolver) lookup(path []string) (interface{}, bool, error) {
+	var node hoconNode = r.root
+	for i, k := range path {
+		obj, ok := node.(*hoconObject)
+		if !ok {
+			// value is known after resolution, e.g. substitution of object
+			v, err := r.resolve(node)
+			if err != nil {
+				return nil, false, err
+			}
+			for _, k := range path[i:] {
+				m, ok := v.(map[string]interface{})
+				if !ok {
+					return nil, false, nil
+				}
+				if v, ok = m[k]; !ok {
+					return nil, false, nil
+				}
+			}
+			return v, true, nil
+		}
+		if node, ok = obj.fields[k]; !ok {
+			return nil, false, nil
+		}
+	}
+	v, err := r.resolve(node)
+	if err != nil || v == hoconUndefined {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// resolveConcat resolves concatenation of values. Objects are
+// merged, arrays joined and other values concatenated as strings.
+func (r *hoconResolver) resolveConcat(c *hoconConcat) (interface{}, error) {
+	var values []interface{}
+	var strs []string
+	var objects, arrays, others int
+	for _, part := range c.parts {
+		v, err := r.resolve(part)
+		if err != nil {
+			return nil, err
+		}
+		if v == hoconUndefined {
+			continue
+		}
+		if s, ok := part.(*hoconScalar); ok {
+			if s.space {
+				strs = append(strs, s.raw)
+				continue
+			}
+			strs = append(strs, s.raw)
+		} else {
+			strs = append(strs, hoconString(v))
+		}
+		switch v.(type) {
+		case map[string]interface{}:
+			objects++
+		case []interface{}:
+			arrays++
+		default:
+			others++
+		}
+		values = append(values, v)
+	}
+
+	switch {
+	case len(values) == 0:
+		return hoconUndefined, nil
+	case len(values) == 1 && len(strs) == 1:
+		return values[0], nil
+	case objects == len(values):
+		res := map[string]interface{}{}
+		for _, v := range values {
+			mergeMaps(res, v.(map[string]interface{}))
+		}
+		return res, nil
+	case arrays == len(values):
+		res := []interface{}{}
+		for _, v := range values {
+			res = append(res, v.([]interface{})...)
+		}
+		return res, nil
+	case objects > 0 || arrays > 0:
+		return nil, errors.Errorf("cannot concatenate objects or arrays with other values")
+	}
+	return strings.Join(strs, ""), nil
+}
+
+func hoconString(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+// mergeMaps merges src into dst, nested maps are merged
+// recursively into copies, as resolved values are shared.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		d, dok := dst[k].(map[string]interface{})
+		s, sok := v.(map[string]interface{})
+		if dok && sok {
+			m := make(map[string]interface{}, len(d))
+			mergeMaps(m, d)
+			mergeMaps(m, s)
+			dst[k] = m
+		} else {
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package fc
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHOCON(t *testing.T) {
+	os.Setenv("GOFC_TEST_HOCON_HOME", "/home/test")
+	defer os.Unsetenv("GOFC_TEST_HOCON_HOME")
+
+	input, err := os.Open("testdata/hocon/app.conf")
+	require.NoError(t, err)
+	defer input.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "hocon",
+		Encoder: "json",
+		Input:   input,
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{
+		"akka": {
+			"loglevel": "DEBUG",
+			"log-dead-letters": "off",
+			"actor": {"provider": "cluster"},
+			"remote": {"port": 2552, "host": "localhost"}
+		},
+		"app": {
+			"version": 1.0,
+			"name": "my app",
+			"host": "localhost",
+			"url": "http://localhost:2552",
+			"greeting": "Hello   World",
+			"path": "/usr/bin:/bin",
+			"home": "/home/test",
+			"quoted.key": true,
+			"tags": ["a", "b", "c"],
+			"multiline": "first\nsecond"
+		},
+		"defaults": {"timeout": "10s", "retries": 3},
+		"service": {"timeout": "10s", "retries": 5}
+	}`, out.String())
+
+	// single comma separates elements, trailing comma is allowed
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "hocon",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("a = [1, 2,\n3\n,]\nb {c = 1, d = 2,},"),
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{"a": [1, 2, 3], "b": {"c": 1, "d": 2}}`, out.String())
+
+	for input, msg := range map[string]string{
+		"a = ${b}":                "substitution ${b} is not defined",
+		"a = ${b}\nb = ${a}":      "cycle in substitution",
+		"a = [1, 2\n":             "line 2: unterminated array",
+		"a = {b = 1} c":           "cannot concatenate objects or arrays with other values",
+		"include required(\"x\")": "cannot include 'x'",
+		"a = [1,,2]":              "line 1: unexpected ',' in array",
+		"a = [,1]":                "unexpected ',' in array",
+		"a = 1,,b = 2":            "unexpected ',', expecting key",
+		"a {b = 1\n,,c = 2}":      "line 2: unexpected ',', expecting key",
+		",a = 1":                  "unexpected ',', expecting key",
+	} {
+		err := testRecoder.Run(&Config{
+			Decoder: "hocon",
+			Encoder: "json",
+			Input:   bytes.NewBufferString(input),
+			Output:  &out,
+		})
+		require.Error(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+}
+
+func TestImportFormat(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "null",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"testdata/hocon/hocon.tpl"},
+		Output:      &out,
+	}))
+	require.Equal(t, "my app DEBUG 5\n", out.String())
+}
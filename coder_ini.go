@@ -0,0 +1,208 @@
+package fc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// coderINI decodes and encodes INI files. Sections are mapped
+// to nested maps, keys with [] suffix (e.g. extension[] = a.so)
+// are collected into lists. All decoded values are strings.
+type coderINI struct{}
+
+func (c *coderINI) Initialize() error {
+	return nil
+}
+
+func (c *coderINI) Names() []string {
+	return []string{"ini"}
+}
+
+func (c *coderINI) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("INI: invalid input argument '%s', no arguments expected", args[0])})
+	}
+
+	res := map[string]interface{}{}
+	section := res
+	scanner := bufio.NewScanner(in)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// backslash at the end of line continues the value
+		for strings.HasSuffix(line, `\`) && scanner.Scan() {
+			lineNum++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(scanner.Text())
+		}
+
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+			continue
+		case line[0] == '[':
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, nil, errors.Errorf("INI: line %d: unterminated section name", lineNum)
+			}
+			name := strings.TrimSpace(line[1:end])
+			s, ok := res[name].(map[string]interface{})
+			if !ok && res[name] != nil {
+				return nil, nil, errors.Errorf("INI: line %d: section '%s' conflicts with key of the same name", lineNum, name)
+			} else if !ok {
+				s = map[string]interface{}{}
+				res[name] = s
+			}
+			section = s
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, nil, errors.Errorf("INI: line %d: expecting 'key = value'", lineNum)
+		}
+		key := strings.TrimSpace(line[:eq])
+		value, err := parseINIValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "INI: line %d", lineNum)
+		}
+		if strings.HasSuffix(key, "[]") {
+			key = strings.TrimSuffix(key, "[]")
+			list, _ := section[key].([]interface{})
+			section[key] = append(list, value)
+		} else {
+			section[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, errors.Annotatef(err, "INI: cannot read input")
+	}
+	return res, nil, nil
+}
+
+// parseINIValue parses quoted or plain value, plain
+// values can be followed by ';' or '#' comment.
+func parseINIValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				return strconv.Unquote(s[:i+1])
+			}
+		}
+		return "", errors.New("unterminated quoted value")
+	case '\'':
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated quoted value")
+		}
+		return s[1 : end+1], nil
+	}
+	for i := 1; i < len(s); i++ {
+		if (s[i] == ';' || s[i] == '#') && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i]), nil
+		}
+	}
+	return s, nil
+}
+
+func (c *coderINI) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	if len(args) > 0 {
+		return errors.Trace(ArgumentError{error: fmt.Sprintf("INI: invalid output argument '%s', no arguments expected", args[0])})
+	}
+	root, ok := normalizeValue(in).(map[string]interface{})
+	if !ok {
+		return errors.Errorf("INI: cannot encode %s, map is expected", valueKind(in))
+	}
+
+	w := bufio.NewWriter(out)
+	var sections []string
+	for _, k := range sortedKeys(root) {
+		if _, ok := root[k].(map[string]interface{}); ok {
+			sections = append(sections, k)
+		} else if err := writeINIKey(w, k, root[k]); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, name := range sections {
+		if i > 0 || len(sections) < len(root) {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s]\n", name)
+		// deeper maps are flattened into dotted keys
+		var err error
+		walkFlat(root[name], "", ".", func(key string, v interface{}) {
+			if err == nil {
+				err = writeINIKey(w, key, v)
+			}
+		})
+		if err != nil {
+			return errors.Annotatef(err, "INI: section '%s'", name)
+		}
+	}
+	return errors.Annotatef(w.Flush(), "INI: cannot write")
+}
+
+func writeINIKey(w io.Writer, key string, v interface{}) error {
+	if list, ok := v.([]interface{}); ok {
+		for _, e := range list {
+			if _, ok := e.(map[string]interface{}); ok {
+				return errors.Errorf("INI: cannot encode list of maps '%s'", key)
+			}
+			fmt.Fprintf(w, "%s[] = %s\n", key, quoteINIValue(e))
+		}
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%s = %s\n", key, quoteINIValue(v))
+	return err
+}
+
+// quoteINIValue quotes value, if it contains
+// characters, which cannot be written as is.
+func quoteINIValue(v interface{}) string {
+	var s string
+	if v != nil {
+		s = fmt.Sprint(v)
+	}
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, "\"';#\\\n\r") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// walkFlat calls fn for every non-map value of in with key joined
+// from map keys using sep. Lists are passed to fn as is.
+func walkFlat(in interface{}, prefix, sep string, fn func(key string, v interface{})) {
+	m, ok := in.(map[string]interface{})
+	if !ok {
+		fn(prefix, in)
+		return
+	}
+	for _, k := range sortedKeys(m) {
+		key := k
+		if prefix != "" {
+			key = prefix + sep + k
+		}
+		walkFlat(m[k], key, sep, fn)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
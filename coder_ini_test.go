@@ -0,0 +1,83 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestINI(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "ini",
+		Encoder: "json",
+		Input: bytes.NewBufferString(`; global settings
+name = app
+path = "C:\\app" ; quoted
+long = first \
+  second
+
+[database]
+# connection
+host = localhost
+password = 'p;a#ss'
+url = http://host/#anchor
+
+[php]
+extension[] = a.so
+extension[] = b.so
+`),
+		Output: &out,
+	}))
+	require.JSONEq(t, `{
+		"name": "app",
+		"path": "C:\\app",
+		"long": "first second",
+		"database": {"host": "localhost", "password": "p;a#ss", "url": "http://host/#anchor"},
+		"php": {"extension": ["a.so", "b.so"]}
+	}`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "ini",
+		Input:   bytes.NewBufferString(`{"name": "app", "note": " a;b ", "db": {"port": 5432, "tls": {"enabled": true}, "hosts": ["a", "b"]}}`),
+		Output:  &out,
+	}))
+	require.Equal(t, `name = app
+note = " a;b "
+
+[db]
+hosts[] = a
+hosts[] = b
+port = 5432
+tls.enabled = true
+`, out.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "ini",
+		Input:   bytes.NewBufferString(`[1, 2]`),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "INI: cannot encode list, map is expected")
+
+	// repeated sections are merged, but cannot replace root keys
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "ini",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("[db]\nhost = a\n[db]\nport = 1\n"),
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{"db": {"host": "a", "port": "1"}}`, out.String())
+
+	err = testRecoder.Run(&Config{
+		Decoder: "ini",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("db = a\n\n[db]\nhost = b\n"),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "INI: line 3: section 'db' conflicts with key of the same name")
+}
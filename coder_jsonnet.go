@@ -0,0 +1,139 @@
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-jsonnet"
+	"github.com/juju/errors"
+)
+
+// coderJsonnet evaluates Jsonnet source into data.
+type coderJsonnet struct {
+	conv *Recoder
+}
+
+func (c *coderJsonnet) Initialize() error {
+	return nil
+}
+
+func (c *coderJsonnet) Names() []string {
+	return []string{"jsonnet"}
+}
+
+func (c *coderJsonnet) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	return c.DecodeContext(context.Background(), in, args)
+}
+
+// DecodeContext is implemented to receive the input as is, relative
+// imports are resolved against the input file, if it is a named file.
+func (c *coderJsonnet) DecodeContext(ctx context.Context, in io.Reader, args []string) (interface{}, interface{}, error) {
+	vm := jsonnet.MakeVM()
+	importer := &jsonnetImporter{conv: c.conv, cache: map[string]jsonnetEntry{}}
+	for _, arg := range args {
+		kind, value := arg, ""
+		if i := strings.Index(arg, "="); i >= 0 {
+			kind, value = arg[:i], arg[i+1:]
+		}
+		switch kind {
+		case "jpath":
+			importer.paths = append(importer.paths, value)
+			continue
+		case "ext-str", "ext-code", "tla-str", "tla-code":
+		default:
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("jsonnet: invalid input argument '%s', supported arguments: 'ext-str=NAME[=VALUE]', 'ext-code=NAME=CODE', 'tla-str=NAME[=VALUE]', 'tla-code=NAME=CODE', 'jpath=DIR'", arg)})
+		}
+
+		// value of string variables without '=' is read from environment
+		name := value
+		if i := strings.Index(value, "="); i >= 0 {
+			name, value = value[:i], value[i+1:]
+		} else if v, ok := os.LookupEnv(name); ok && strings.HasSuffix(kind, "-str") {
+			value = v
+		} else {
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("jsonnet: invalid input argument '%s', value is not set", arg)})
+		}
+		switch kind {
+		case "ext-str":
+			vm.ExtVar(name, value)
+		case "ext-code":
+			vm.ExtCode(name, value)
+		case "tla-str":
+			vm.TLAVar(name, value)
+		case "tla-code":
+			vm.TLACode(name, value)
+		}
+	}
+	vm.Importer(importer)
+
+	src, err := ioutil.ReadAll(&ctxReader{ctx: ctx, r: in})
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "jsonnet: cannot read input")
+	}
+	var js string
+	if name := inputFileName(in); name != "" {
+		// named input is evaluated as file, already read content is
+		// added to the importer cache, so it is not read again
+		filename := filepath.Clean(name)
+		importer.cache[filename] = jsonnetEntry{contents: jsonnet.MakeContentsRaw(src)}
+		js, err = vm.EvaluateFile(filename)
+	} else {
+		js, err = vm.EvaluateAnonymousSnippet("<stdin>", string(src))
+	}
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot evaluate jsonnet")
+	}
+	var out interface{}
+	if err = json.Unmarshal([]byte(js), &out); err != nil {
+		return nil, nil, errors.Annotatef(err, "jsonnet: cannot decode result")
+	}
+	return out, nil, nil
+}
+
+type jsonnetEntry struct {
+	contents jsonnet.Contents
+	err      error
+}
+
+// jsonnetImporter imports files from the recoder file system,
+// relative to the importing file and then library paths.
+type jsonnetImporter struct {
+	conv  *Recoder
+	paths []string
+	cache map[string]jsonnetEntry
+}
+
+func (i *jsonnetImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	dirs := []string{filepath.Dir(importedFrom)}
+	if filepath.IsAbs(importedPath) {
+		dirs = []string{""}
+	} else {
+		dirs = append(dirs, i.paths...)
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, importedPath)
+		entry, ok := i.cache[path]
+		if !ok {
+			var data []byte
+			data, entry.err = i.conv.readFile(path)
+			if entry.err == nil {
+				i.conv.fileAccessed(path)
+				entry.contents = jsonnet.MakeContentsRaw(data)
+			}
+			i.cache[path] = entry
+		}
+		if entry.err == nil {
+			return entry.contents, path, nil
+		}
+		if !os.IsNotExist(entry.err) {
+			return jsonnet.Contents{}, "", errors.Annotatef(entry.err, "cannot import '%s'", importedPath)
+		}
+	}
+	return jsonnet.Contents{}, "", errors.Errorf("couldn't open import '%s': no match locally or in library paths", importedPath)
+}
@@ -0,0 +1,61 @@
+package fc
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonnet(t *testing.T) {
+	os.Setenv("GOFC_TEST_JSONNET_ENV", "prod")
+	defer os.Unsetenv("GOFC_TEST_JSONNET_ENV")
+
+	input, err := os.Open("testdata/jsonnet/app.jsonnet")
+	require.NoError(t, err)
+	defer input.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "jsonnet",
+		DecoderArgs: []string{"ext-str=name=app", "ext-str=env=GOFC_TEST_JSONNET_ENV", "tla-code=replicas=1+2", "jpath=testdata/jsonnet/lib"},
+		Encoder:     "yaml",
+		Input:       input,
+		Output:      &out,
+	}))
+	require.Equal(t, `env: GOFC_TEST_JSONNET_ENV
+labels:
+  team: core
+name: app
+replicas: 3
+timeout: 10s
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "jsonnet",
+		DecoderArgs: []string{"ext-str=GOFC_TEST_JSONNET_ENV"},
+		Encoder:     "json",
+		Input:       bytes.NewBufferString(`{ env: std.extVar("GOFC_TEST_JSONNET_ENV"), list: [x * 2 for x in [1, 2]] }`),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `{"env": "prod", "list": [2, 4]}`, out.String())
+
+	err = testRecoder.Run(&Config{
+		Decoder: "jsonnet",
+		Encoder: "json",
+		Input:   bytes.NewBufferString(`{ a: error "broken" }`),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "RUNTIME ERROR: broken")
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "null",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"testdata/jsonnet/jsonnet.tpl"},
+		Output:      &out,
+	}))
+	require.Equal(t, "timeout: 10s\n", out.String())
+}
@@ -0,0 +1,187 @@
+package fc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// jsonSchemaEnumLimit is the default maximal number
+// of distinct string values, which are turned into enum.
+const jsonSchemaEnumLimit = 5
+
+// coderJSONSchema infers JSON Schema from sample documents.
+type coderJSONSchema struct{}
+
+func (c *coderJSONSchema) Initialize() error {
+	return nil
+}
+
+func (c *coderJSONSchema) Names() []string {
+	return []string{"jsonschema"}
+}
+
+func (c *coderJSONSchema) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	var multi bool
+	enumLimit := jsonSchemaEnumLimit
+	for _, arg := range args {
+		switch {
+		case arg == "multi":
+			multi = true
+		case strings.HasPrefix(arg, "enum="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "enum="))
+			if err != nil || n < 0 {
+				return errors.Trace(ArgumentError{error: fmt.Sprintf("jsonschema: invalid enum limit '%s'", arg)})
+			}
+			enumLimit = n
+		default:
+			return errors.Trace(ArgumentError{error: fmt.Sprintf("jsonschema: invalid output argument '%s', supported arguments: 'multi', 'enum=N'", arg)})
+		}
+	}
+
+	docs := []interface{}{normalizeValue(in)}
+	if multi {
+		var ok bool
+		if docs, ok = docs[0].([]interface{}); !ok {
+			return errors.Errorf("jsonschema: list of documents is expected with 'multi' argument")
+		}
+	}
+
+	node := &schemaNode{}
+	for _, doc := range docs {
+		node.observe(doc)
+	}
+	schema := node.schema(enumLimit)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return errors.Annotatef(encoder.Encode(schema), "jsonschema: cannot write")
+}
+
+// schemaNode accumulates observations of values at the same location.
+type schemaNode struct {
+	seen  int
+	types map[string]bool
+
+	// objects is the number of observed objects,
+	// it's used to find keys present in all of them.
+	objects    int
+	properties map[string]*schemaNode
+
+	items *schemaNode
+
+	strings    int
+	stringEnum map[string]bool
+}
+
+func (n *schemaNode) observe(v interface{}) {
+	n.seen++
+	if n.types == nil {
+		n.types = make(map[string]bool)
+	}
+	switch val := v.(type) {
+	case nil:
+		n.types["null"] = true
+	case bool:
+		n.types["boolean"] = true
+	case string:
+		n.types["string"] = true
+		n.strings++
+		if n.stringEnum == nil {
+			n.stringEnum = make(map[string]bool)
+		}
+		n.stringEnum[val] = true
+	case map[string]interface{}:
+		n.types["object"] = true
+		n.objects++
+		if n.properties == nil {
+			n.properties = make(map[string]*schemaNode)
+		}
+		for k, e := range val {
+			p, ok := n.properties[k]
+			if !ok {
+				p = &schemaNode{}
+				n.properties[k] = p
+			}
+			p.observe(e)
+		}
+	case []interface{}:
+		n.types["array"] = true
+		for _, e := range val {
+			if n.items == nil {
+				n.items = &schemaNode{}
+			}
+			n.items.observe(e)
+		}
+	default:
+		if f, ok := toFloat(v); ok && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			n.types["integer"] = true
+		} else if ok {
+			n.types["number"] = true
+		} else {
+			// e.g. dates of TOML documents
+			n.types["string"] = true
+		}
+	}
+}
+
+func (n *schemaNode) schema(enumLimit int) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	// integer is a subset of number
+	if n.types["integer"] && n.types["number"] {
+		delete(n.types, "integer")
+	}
+	types := make([]string, 0, len(n.types))
+	for t := range n.types {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	switch len(types) {
+	case 0:
+		// no observations, e.g. items of empty list
+		return res
+	case 1:
+		res["type"] = types[0]
+	default:
+		res["type"] = types
+	}
+
+	if n.types["object"] {
+		props := make(map[string]interface{}, len(n.properties))
+		var required []string
+		for k, p := range n.properties {
+			props[k] = p.schema(enumLimit)
+			if p.seen == n.objects {
+				required = append(required, k)
+			}
+		}
+		sort.Strings(required)
+		res["properties"] = props
+		if len(required) > 0 {
+			res["required"] = required
+		}
+	}
+	if n.items != nil {
+		res["items"] = n.items.schema(enumLimit)
+	}
+
+	// strings are turned into enum, only if values are repeated,
+	// otherwise every single-document string would become an enum
+	if n.strings == n.seen && len(n.stringEnum) <= enumLimit && n.strings > len(n.stringEnum) {
+		enum := make([]string, 0, len(n.stringEnum))
+		for s := range n.stringEnum {
+			enum = append(enum, s)
+		}
+		sort.Strings(enum)
+		res["enum"] = enum
+	}
+	return res
+}
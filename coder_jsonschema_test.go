@@ -0,0 +1,90 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		DecoderArgs: []string{"multi"},
+		Encoder:     "jsonschema",
+		EncoderArgs: []string{"multi"},
+		Input: bytes.NewBufferString(`
+name: web
+env: prod
+replicas: 2
+ports: [80, 443]
+---
+name: api
+env: prod
+replicas: 1.5
+debug: true
+ports: []
+---
+name: worker
+env: dev
+replicas: 1
+ports: [8080]
+`),
+		Output: &out,
+	}))
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"env": {"type": "string", "enum": ["dev", "prod"]},
+			"replicas": {"type": "number"},
+			"debug": {"type": "boolean"},
+			"ports": {"type": "array", "items": {"type": "integer"}}
+		},
+		"required": ["env", "name", "ports", "replicas"]
+	}`, out.String())
+
+	// inferred schema accepts the sample
+	schema, err := CompileSchema(decodeJSON(t, out.String()))
+	require.NoError(t, err)
+	require.NoError(t, schema.Validate(map[string]interface{}{
+		"name": "db", "env": "dev", "replicas": 3, "ports": []interface{}{5432},
+	}))
+}
+
+func TestJSONSchemaArgs(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "jsonschema",
+		EncoderArgs: []string{"enum=0"},
+		Input:       bytes.NewBufferString(`{"a": ["x", "x", null]}`),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {"a": {"type": "array", "items": {"type": ["null", "string"]}}},
+		"required": ["a"]
+	}`, out.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "jsonschema",
+		EncoderArgs: []string{"multi"},
+		Input:       bytes.NewBufferString(`{}`),
+		Output:      &out,
+	})
+	require.Error(t, err)
+}
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	data, _, err := testRecoder.Decode(&Config{
+		Decoder: "json",
+		Input:   bytes.NewBufferString(s),
+	})
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,115 @@
+package fc
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/ugorji/go/codec"
+)
+
+// coderMsgpack decodes and encodes MessagePack.
+type coderMsgpack struct {
+	handle *codec.MsgpackHandle
+}
+
+func (c *coderMsgpack) Initialize() error {
+	c.handle = &codec.MsgpackHandle{WriteExt: true, PositiveIntUnsigned: true}
+	c.handle.Canonical = true
+	return nil
+}
+
+func (c *coderMsgpack) Names() []string {
+	return []string{"msgpack"}
+}
+
+func (c *coderMsgpack) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("msgpack: invalid input argument '%s', no arguments expected", args[0])})
+	}
+	var out interface{}
+	if err := codec.NewDecoder(in, c.handle).Decode(&out); err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot decode msgpack")
+	}
+	return codecOutput(out), nil, nil
+}
+
+func (c *coderMsgpack) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	integers, err := parseCodecArgs("msgpack", args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(codec.NewEncoder(out, c.handle).Encode(codecInput(in, integers)), "msgpack: cannot encode")
+}
+
+// parseCodecArgs parses output arguments of msgpack and CBOR encoders.
+func parseCodecArgs(name string, args []string) (integers bool, err error) {
+	for _, arg := range args {
+		switch arg {
+		case "integers":
+			integers = true
+		default:
+			return false, errors.Trace(ArgumentError{error: fmt.Sprintf("%s: invalid output argument '%s', supported arguments: 'integers'", name, arg)})
+		}
+	}
+	return integers, nil
+}
+
+// codecOutput converts value decoded by codec into the generic
+// model, map keys are converted to strings and byte strings to Binary.
+func codecOutput(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if b, ok := k.([]byte); ok {
+				k = string(b)
+			}
+			res[fmt.Sprint(k)] = codecOutput(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = codecOutput(e)
+		}
+		return res
+	case []byte:
+		return Binary(v)
+	}
+	return in
+}
+
+// codecInput prepares value for codec encoding, Binary is written
+// as byte string. With integers whole floats are written as integers.
+func codecInput(in interface{}, integers bool) interface{} {
+	switch v := normalizeValue(in).(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = codecInput(e, integers)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = codecInput(e, integers)
+		}
+		return v
+	case Binary:
+		return []byte(v)
+	case float64:
+		if integers && isWholeFloat(v) {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isWholeFloat reports whether v can be written as integer. It is used by
+// opt-in 'integers' encoder arguments, as JSON and HCL decoders do not
+// distinguish integers from floats.
+func isWholeFloat(v float64) bool {
+	return v == math.Trunc(v) && math.Abs(v) < 1<<63 && !(v == 0 && math.Signbit(v))
+}
@@ -0,0 +1,76 @@
+package fc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgpack(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "msgpack",
+		EncoderArgs: []string{"integers"},
+		Input:       bytes.NewBufferString(`{"port": 8080, "ratio": 0.5, "name": "app", "tags": ["a", null, true]}`),
+		Output:      &out,
+	}))
+	// keys are sorted, with integers argument whole numbers are written as integers
+	require.Equal(t, "84a46e616d65a3617070a4706f7274cd1f90a5726174696fcb3fe0000000000000a47461677393a161c0c3", hex.EncodeToString(out.Bytes()))
+
+	var out2 bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "msgpack",
+		Encoder: "yaml",
+		Input:   &out,
+		Output:  &out2,
+	}))
+	require.Equal(t, `name: app
+port: 8080
+ratio: 0.5
+tags:
+- a
+- null
+- true
+`, out2.String())
+
+	// {1: bin "\x00\x01", "t": timestamp 1}
+	input, _ := hex.DecodeString("8201c4020001a174d6ff00000001")
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "msgpack",
+		Encoder: "json",
+		Input:   bytes.NewBuffer(input),
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{"1": "AAE=", "t": "1970-01-01T00:00:01Z"}`, out.String())
+
+	// binary is written back as bin, map keys as strings
+	data, _, err := testRecoder.Decode(&Config{Decoder: "msgpack", Input: bytes.NewBuffer(input)})
+	require.NoError(t, err)
+	require.Equal(t, Binary{0, 1}, data.(map[string]interface{})["1"])
+	out.Reset()
+	require.NoError(t, testRecoder.Encode(&Config{Encoder: "msgpack", Output: &out}, data, nil))
+	require.Equal(t, "82a131c4020001a174d6ff00000001", hex.EncodeToString(out.Bytes()))
+
+	var cfg struct {
+		Data []byte `json:"1"`
+	}
+	require.NoError(t, testRecoder.DecodeInto(&Config{Decoder: "msgpack", Input: bytes.NewBuffer(input)}, &cfg))
+	require.Equal(t, []byte{0, 1}, cfg.Data)
+
+	// floats are kept by default
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "yaml",
+		Encoder: "msgpack",
+		Input:   bytes.NewBufferString("port: 8080\nratio: 1.0\n"),
+		Output:  &out,
+	}))
+	require.Equal(t, "82a4706f7274cd1f90a5726174696fcb3ff0000000000000", hex.EncodeToString(out.Bytes()))
+	data, _, err = testRecoder.Decode(&Config{Decoder: "msgpack", Input: &out})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"port": uint64(8080), "ratio": float64(1)}, data)
+}
@@ -9,7 +9,7 @@ import (
 
 func TestNULL(t *testing.T) {
 	var out bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "n",
 		Encoder: "j",
 		Input:   nil,
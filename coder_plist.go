@@ -0,0 +1,178 @@
+package fc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"howett.net/plist"
+)
+
+// coderPlist decodes and encodes Apple property lists. Data values are
+// decoded as Binary, dates as time values, integers and reals are kept apart.
+type coderPlist struct{}
+
+func (c *coderPlist) Initialize() error {
+	return nil
+}
+
+func (c *coderPlist) Names() []string {
+	return []string{"plist"}
+}
+
+// Decode reads XML, binary, OpenStep and GNUstep
+// property lists, format is detected automatically.
+func (c *coderPlist) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("plist: invalid input argument '%s', no arguments are supported", args[0])})
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "plist: cannot read input")
+	}
+	var out interface{}
+	if _, err = plist.Unmarshal(data, &out); err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot decode plist")
+	}
+	return plistOutput(out), nil, nil
+}
+
+// plistOutput converts decoded values into types of other decoders.
+func plistOutput(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = plistOutput(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = plistOutput(e)
+		}
+		return v
+	case []byte:
+		return Binary(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case plist.UID:
+		return uint64(v)
+	case float32:
+		// keep the shortest representation, e.g. 0.1 instead of 0.10000000149011612
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+		return f
+	default:
+		return v
+	}
+}
+
+// plistArgs are arguments of plist encoder.
+type plistArgs struct {
+	format   int
+	integers bool
+	data     map[string]bool
+	date     map[string]bool
+}
+
+// Encode writes XML property list, unless 'binary' argument is set.
+// As plist has no null, null values of maps are omitted. Floats are
+// written as reals, with 'integers' argument whole numbers are integers.
+func (c *coderPlist) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	opts := &plistArgs{format: plist.XMLFormat, data: map[string]bool{}, date: map[string]bool{}}
+	for _, arg := range args {
+		switch {
+		case arg == "binary":
+			opts.format = plist.BinaryFormat
+		case arg == "integers":
+			opts.integers = true
+		case strings.HasPrefix(arg, "data="):
+			opts.data[strings.TrimPrefix(arg, "data=")] = true
+		case strings.HasPrefix(arg, "date="):
+			opts.date[strings.TrimPrefix(arg, "date=")] = true
+		default:
+			return errors.Trace(ArgumentError{error: fmt.Sprintf("plist: invalid output argument '%s', supported arguments: 'binary', 'integers', 'data=KEY', 'date=KEY'", arg)})
+		}
+	}
+
+	v, err := opts.input(nil, "", in)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var data []byte
+	if opts.format == plist.XMLFormat {
+		if data, err = plist.MarshalIndent(v, opts.format, "\t"); err == nil {
+			data = append(data, '\n')
+		}
+	} else {
+		data, err = plist.Marshal(v, opts.format)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot encode plist")
+	}
+	_, err = out.Write(data)
+	return errors.Annotatef(err, "plist: cannot write")
+}
+
+// input converts value into plist types, strings of keys set
+// with 'data=KEY' and 'date=KEY' are parsed as base64 and RFC 3339.
+func (o *plistArgs) input(path []interface{}, key string, in interface{}) (interface{}, error) {
+	switch v := normalizeValue(in).(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if e == nil {
+				continue
+			}
+			var err error
+			if res[k], err = o.input(appendPath(path, k), k, e); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			var err error
+			if res[i], err = o.input(appendPath(path, i), key, e); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case nil:
+		return nil, errors.Errorf("plist: %s: null values are not supported", Change{Path: path}.PathString())
+	case Binary:
+		return []byte(v), nil
+	case string:
+		switch {
+		case o.data[key]:
+			// long values are usually wrapped
+			b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(v), ""))
+			if err != nil {
+				return nil, errors.Errorf("plist: %s: invalid base64 data", Change{Path: path}.PathString())
+			}
+			return b, nil
+		case o.date[key]:
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errors.Errorf("plist: %s: invalid date '%s', RFC 3339 format is expected", Change{Path: path}.PathString(), v)
+			}
+			return t, nil
+		}
+		return v, nil
+	case float64:
+		if o.integers && isWholeFloat(v) {
+			return int64(v), nil
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
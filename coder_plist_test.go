@@ -0,0 +1,91 @@
+package fc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlist(t *testing.T) {
+	input, err := os.Open("testdata/plist/profile.plist")
+	require.NoError(t, err)
+	defer input.Close()
+
+	data, _, err := testRecoder.Decode(&Config{Decoder: "plist", Input: input})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"PayloadContent": []interface{}{
+			map[string]interface{}{
+				"PayloadContent": Binary("hello world"),
+				"PayloadType":    "com.apple.security.root",
+				"PayloadVersion": int64(1),
+			},
+		},
+		"PayloadDisplayName": "Root CA",
+		"PayloadScope":       "System",
+		"PayloadVersion":     int64(1),
+		"RemovalDate":        time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		"Ratio":              float64(1),
+		"Offset":             int64(-5),
+		"Enabled":            true,
+	}, data)
+
+	// binary round trip keeps all types
+	var bin bytes.Buffer
+	require.NoError(t, testRecoder.Encode(&Config{Encoder: "plist", EncoderArgs: []string{"binary"}, Output: &bin}, data, nil))
+	require.Equal(t, "bplist00", bin.String()[:8])
+	res, _, err := testRecoder.Decode(&Config{Decoder: "plist", Input: &bin})
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	// data and dates of text formats are set by key
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "plist",
+		EncoderArgs: []string{"data=PayloadContent", "date=RemovalDate"},
+		Input: bytes.NewBufferString(`
+PayloadContent:
+- PayloadContent: |
+    aGVsbG8g
+    d29ybGQ=
+  PayloadType: com.apple.security.root
+  PayloadVersion: 1
+PayloadDisplayName: Root CA
+PayloadScope: System
+PayloadVersion: 1
+RemovalDate: 2030-01-01T00:00:00Z
+Ratio: 1.0
+Offset: -5
+Enabled: true
+Skipped: null
+`),
+		Output: &out,
+	}))
+	expected, err := ioutil.ReadFile("testdata/plist/profile.out.plist")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "plist",
+		EncoderArgs: []string{"integers"},
+		Input:       bytes.NewBufferString(`{"port": 8080, "ratio": 0.5}`),
+		Output:      &out,
+	}))
+	require.Contains(t, out.String(), "<integer>8080</integer>")
+	require.Contains(t, out.String(), "<real>0.5</real>")
+
+	err = testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "plist",
+		Input:   bytes.NewBufferString(`{"list": [null]}`),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "plist: .list[0]: null values are not supported")
+}
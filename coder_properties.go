@@ -0,0 +1,271 @@
+package fc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/juju/errors"
+)
+
+var propertiesIndex = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+
+// coderProperties decodes and encodes Java properties files.
+// Dotted keys are expanded into nested maps and keys with index
+// suffix, e.g. servers[0], into lists, unless 'flat' argument
+// is set. All decoded values are strings.
+type coderProperties struct{}
+
+func (c *coderProperties) Initialize() error {
+	return nil
+}
+
+func (c *coderProperties) Names() []string {
+	return []string{"properties"}
+}
+
+func (c *coderProperties) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	var flat bool
+	for _, arg := range args {
+		switch arg {
+		case "flat":
+			flat = true
+		default:
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("properties: invalid input argument '%s', supported arguments: 'flat'", arg)})
+		}
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "properties: cannot read input")
+	}
+	res := map[string]interface{}{}
+	for _, line := range propertiesLines(string(data)) {
+		key, value := parsePropertiesLine(line)
+		if flat {
+			res[key] = value
+		} else if err = setPropertiesKey(res, key, value); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	if flat {
+		return res, nil, nil
+	}
+	return propertiesLists(res), nil, nil
+}
+
+// propertiesLines returns logical lines of the file, lines ending with
+// backslash are joined with the following ones, comments are skipped.
+func propertiesLines(data string) []string {
+	var lines []string
+	var buf strings.Builder
+	var cont bool
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimLeft(strings.TrimRight(line, "\r"), " \t\f")
+		if !cont && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		// odd number of trailing backslashes continues the line
+		n := len(line) - len(strings.TrimRight(line, `\`))
+		if cont = n%2 == 1; cont {
+			line = line[:len(line)-1]
+		}
+		buf.WriteString(line)
+		if !cont {
+			lines = append(lines, buf.String())
+			buf.Reset()
+		}
+	}
+	if buf.Len() > 0 {
+		lines = append(lines, buf.String())
+	}
+	return lines
+}
+
+// parsePropertiesLine splits logical line into unescaped key and value.
+// Key ends with the first unescaped '=', ':' or whitespace.
+func parsePropertiesLine(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+		} else if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return unescapeProperties(line[:end]), unescapeProperties(rest)
+}
+
+func unescapeProperties(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			buf.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			buf.WriteByte('\t')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'u':
+			if i+5 <= len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 16); err == nil {
+					r1 := rune(r)
+					i += 4
+					// surrogate pairs are written as two escapes
+					if utf16.IsSurrogate(r1) && i+7 <= len(s) && s[i+1:i+3] == `\u` {
+						if r2, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+							r1 = utf16.DecodeRune(r1, rune(r2))
+							i += 6
+						}
+					}
+					buf.WriteRune(r1)
+					continue
+				}
+			}
+			buf.WriteByte('u')
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+	return buf.String()
+}
+
+// setPropertiesKey sets value under dotted key. List indexes
+// are kept as "[N]" map keys until the map is complete.
+func setPropertiesKey(res map[string]interface{}, key, value string) error {
+	var path []string
+	for _, part := range strings.Split(key, ".") {
+		var idx []string
+		for m := propertiesIndex.FindStringSubmatch(part); m != nil; m = propertiesIndex.FindStringSubmatch(part) {
+			part = m[1]
+			idx = append([]string{"[" + m[2] + "]"}, idx...)
+		}
+		path = append(append(path, part), idx...)
+	}
+
+	m := res
+	for i, k := range path[:len(path)-1] {
+		switch v := m[k].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			m[k], m = next, next
+		case map[string]interface{}:
+			m = v
+		default:
+			return errors.Errorf("properties: key '%s' conflicts with value of '%s'", key, strings.Join(path[:i+1], "."))
+		}
+	}
+	last := path[len(path)-1]
+	if _, ok := m[last].(map[string]interface{}); ok {
+		return errors.Errorf("properties: value of '%s' conflicts with nested keys", key)
+	}
+	m[last] = value
+	return nil
+}
+
+// propertiesLists converts maps with only "[N]" keys into lists.
+func propertiesLists(in interface{}) interface{} {
+	m, ok := in.(map[string]interface{})
+	if !ok {
+		return in
+	}
+	indexes := make([]int, 0, len(m))
+	for k, v := range m {
+		m[k] = propertiesLists(v)
+		if len(k) > 2 && k[0] == '[' && k[len(k)-1] == ']' {
+			if i, err := strconv.Atoi(k[1 : len(k)-1]); err == nil {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	if len(m) == 0 || len(indexes) < len(m) {
+		return m
+	}
+	sort.Ints(indexes)
+	list := make([]interface{}, len(indexes))
+	for i, idx := range indexes {
+		list[i] = m[fmt.Sprintf("[%d]", idx)]
+	}
+	return list
+}
+
+func (c *coderProperties) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	if len(args) > 0 {
+		return errors.Trace(ArgumentError{error: fmt.Sprintf("properties: invalid output argument '%s', no arguments expected", args[0])})
+	}
+	root, ok := normalizeValue(in).(map[string]interface{})
+	if !ok {
+		return errors.Errorf("properties: cannot encode %s, map is expected", valueKind(in))
+	}
+
+	w := bufio.NewWriter(out)
+	var write func(key string, v interface{})
+	write = func(key string, v interface{}) {
+		if list, ok := v.([]interface{}); ok {
+			for i, e := range list {
+				walkFlat(e, fmt.Sprintf("%s[%d]", key, i), ".", write)
+			}
+			return
+		}
+		var s string
+		if v != nil {
+			s = fmt.Sprint(v)
+		}
+		fmt.Fprintf(w, "%s=%s\n", escapeProperties(key, true), escapeProperties(s, false))
+	}
+	walkFlat(root, "", ".", write)
+	return errors.Annotatef(w.Flush(), "properties: cannot write")
+}
+
+// escapeProperties escapes special and non-ASCII characters,
+// separators are escaped in keys, leading space in values.
+func escapeProperties(s string, key bool) string {
+	var buf strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			buf.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r):
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&buf, `\u%04x`, u)
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,85 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProperties = `# server settings
+! also a comment
+server.port = 8080
+server.name:main
+server.hosts[0]=a
+server.hosts[1]=b
+message = multi \
+          line\nvalue
+key\ with\ spaces = caf\u00e9
+path=C:\\app
+`
+
+func TestProperties(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "properties",
+		Encoder: "json",
+		Input:   bytes.NewBufferString(testProperties),
+		Output:  &out,
+	}))
+	require.JSONEq(t, `{
+		"server": {"port": "8080", "name": "main", "hosts": ["a", "b"]},
+		"message": "multi line\nvalue",
+		"key with spaces": "café",
+		"path": "C:\\app"
+	}`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "properties",
+		DecoderArgs: []string{"flat"},
+		Encoder:     "json",
+		Input:       bytes.NewBufferString(testProperties),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `{
+		"server.port": "8080",
+		"server.name": "main",
+		"server.hosts[0]": "a",
+		"server.hosts[1]": "b",
+		"message": "multi line\nvalue",
+		"key with spaces": "café",
+		"path": "C:\\app"
+	}`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "properties",
+		Input:   bytes.NewBufferString(`{"server": {"port": 8080, "hosts": ["a", {"name": "b"}]}, "a key": " café\n", "empty": null}`),
+		Output:  &out,
+	}))
+	require.Equal(t, `a\ key=\ caf\u00e9\n
+empty=
+server.hosts[0]=a
+server.hosts[1].name=b
+server.port=8080
+`, out.String())
+
+	var out2 bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder: "properties",
+		Encoder: "json",
+		Input:   &out,
+		Output:  &out2,
+	}))
+	require.JSONEq(t, `{"server": {"port": "8080", "hosts": ["a", {"name": "b"}]}, "a key": " café\n", "empty": ""}`, out2.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder: "properties",
+		Encoder: "json",
+		Input:   bytes.NewBufferString("a=1\na.b=2\n"),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "properties: key 'a.b' conflicts with value of 'a'")
+}
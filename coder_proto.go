@@ -0,0 +1,186 @@
+package fc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/juju/errors"
+)
+
+// coderProto decodes and encodes Protocol Buffers messages, described
+// by compiled FileDescriptorSet (protoc --include_imports -o FILE).
+// Messages are converted using proto3 JSON mapping.
+type coderProto struct {
+	conv *Recoder
+}
+
+func (c *coderProto) Initialize() error {
+	return nil
+}
+
+func (c *coderProto) Names() []string {
+	return []string{"proto"}
+}
+
+// protoArgs are arguments of proto coder.
+type protoArgs struct {
+	descriptor string
+	message    string
+	text       bool
+	defaults   bool
+}
+
+func parseProtoArgs(args []string, direction string) (*protoArgs, error) {
+	res := &protoArgs{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "descriptor="):
+			res.descriptor = strings.TrimPrefix(arg, "descriptor=")
+		case strings.HasPrefix(arg, "message="):
+			res.message = strings.TrimPrefix(arg, "message=")
+		case arg == "text":
+			res.text = true
+		case arg == "defaults" && direction == "input":
+			res.defaults = true
+		default:
+			supported := "'descriptor=FILE', 'message=NAME', 'text'"
+			if direction == "input" {
+				supported += ", 'defaults'"
+			}
+			return nil, errors.Trace(ArgumentError{error: fmt.Sprintf("proto: invalid %s argument '%s', supported arguments: %s", direction, arg, supported)})
+		}
+	}
+	if res.descriptor == "" || res.message == "" {
+		return nil, errors.Trace(ArgumentError{error: "proto: 'descriptor=FILE' and 'message=NAME' arguments are required"})
+	}
+	return res, nil
+}
+
+// newMessage creates empty dynamic message described in descriptor file.
+func (c *coderProto) newMessage(args *protoArgs) (*dynamic.Message, error) {
+	data, err := c.conv.readFile(args.descriptor)
+	if err != nil {
+		return nil, errors.Annotatef(err, "proto: cannot read descriptor")
+	}
+	c.conv.fileAccessed(args.descriptor)
+
+	var set dpb.FileDescriptorSet
+	if err = proto.Unmarshal(data, &set); err != nil {
+		return nil, errors.Annotatef(err, "proto: cannot decode descriptor '%s'", args.descriptor)
+	}
+	files, err := desc.CreateFileDescriptorsFromSet(&set)
+	if err != nil {
+		return nil, errors.Annotatef(err, "proto: invalid descriptor '%s'", args.descriptor)
+	}
+	name := strings.TrimPrefix(args.message, ".")
+	for _, file := range files {
+		if md := file.FindMessage(name); md != nil {
+			return dynamic.NewMessage(md), nil
+		}
+	}
+	return nil, errors.Errorf("proto: message '%s' is not found in '%s'", name, args.descriptor)
+}
+
+func (c *coderProto) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	opts, err := parseProtoArgs(args, "input")
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	msg, err := c.newMessage(opts)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "proto: cannot read input")
+	}
+	if opts.text {
+		err = msg.UnmarshalText(stripTextComments(data))
+	} else {
+		err = msg.Unmarshal(data)
+	}
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot decode proto message '%s'", opts.message)
+	}
+
+	js, err := msg.MarshalJSONPB(&jsonpb.Marshaler{EmitDefaults: opts.defaults})
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "proto: cannot convert message")
+	}
+	var out interface{}
+	if err = json.Unmarshal(js, &out); err != nil {
+		return nil, nil, errors.Annotatef(err, "proto: cannot convert message")
+	}
+	return out, nil, nil
+}
+
+func (c *coderProto) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	opts, err := parseProtoArgs(args, "output")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	msg, err := c.newMessage(opts)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	js, err := json.Marshal(normalizeValue(in))
+	if err != nil {
+		return errors.Annotatef(err, "proto: cannot convert input")
+	}
+	if err = msg.UnmarshalJSONPB(&jsonpb.Unmarshaler{}, js); err != nil {
+		return errors.Annotatef(err, "proto: cannot convert input to message '%s'", opts.message)
+	}
+
+	var data []byte
+	if opts.text {
+		if data, err = msg.MarshalTextIndent(); len(data) > 0 {
+			data = append(data, '\n')
+		}
+	} else {
+		data, err = msg.MarshalDeterministic()
+	}
+	if err != nil {
+		return errors.Annotatef(err, "proto: cannot encode message '%s'", opts.message)
+	}
+	_, err = io.Copy(out, bytes.NewReader(data))
+	return errors.Annotatef(err, "proto: cannot write")
+}
+
+// stripTextComments removes '#' comments from text format message,
+// as they are not supported by the text parser.
+func stripTextComments(data []byte) []byte {
+	res := make([]byte, 0, len(data))
+	var quote byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0 && c == '\\' && i+1 < len(data):
+			res = append(res, c, data[i+1])
+			i++
+			continue
+		case quote != 0 && (c == quote || c == '\n'):
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				res = append(res, '\n')
+			}
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
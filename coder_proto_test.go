@@ -0,0 +1,109 @@
+package fc
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testProtoArgs = []string{"descriptor=testdata/proto/config.pb", "message=app.Config"}
+
+func TestProto(t *testing.T) {
+	input, err := os.Open("testdata/proto/config.textproto")
+	require.NoError(t, err)
+	defer input.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "proto",
+		DecoderArgs: append(testProtoArgs, "text"),
+		Encoder:     "json",
+		Input:       input,
+		Output:      &out,
+	}))
+	const expected = `{
+		"name": "app",
+		"replicas": 3,
+		"maxBytes": "1048576",
+		"ratio": 0.5,
+		"hosts": ["#a", "b"],
+		"labels": {"team": "core"},
+		"mode": "MODE_ACTIVE",
+		"database": {"host": "db", "port": 5432},
+		"timeout": "1.500s",
+		"secret": "AAE="
+	}`
+	require.JSONEq(t, expected, out.String())
+
+	// JSON -> binary -> JSON
+	var bin bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "proto",
+		EncoderArgs: testProtoArgs,
+		Input:       &out,
+		Output:      &bin,
+	}))
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "proto",
+		DecoderArgs: testProtoArgs,
+		Encoder:     "json",
+		Input:       &bin,
+		Output:      &out,
+	}))
+	require.JSONEq(t, expected, out.String())
+
+	// YAML -> text, field names are accepted in both forms
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "proto",
+		EncoderArgs: append(testProtoArgs, "text"),
+		Input:       bytes.NewBufferString("name: app\nmax_bytes: 10\ndatabase:\n  port: 5432\n"),
+		Output:      &out,
+	}))
+	require.Equal(t, "name: \"app\"\nmax_bytes: 10\ndatabase: <\n  port: 5432\n>\n", out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "proto",
+		DecoderArgs: append(testProtoArgs, "text", "defaults"),
+		Encoder:     "json",
+		Input:       bytes.NewBufferString(`name: "app"`),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `{
+		"name": "app",
+		"replicas": 0,
+		"maxBytes": "0",
+		"ratio": 0,
+		"debug": false,
+		"hosts": [],
+		"labels": {},
+		"mode": "MODE_UNSPECIFIED",
+		"database": null,
+		"timeout": null,
+		"secret": ""
+	}`, out.String())
+
+	err = testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "proto",
+		EncoderArgs: append(testProtoArgs, "text"),
+		Input:       bytes.NewBufferString("unknown: 1\n"),
+		Output:      &out,
+	})
+	require.Contains(t, err.Error(), "proto: cannot convert input to message 'app.Config'")
+
+	err = testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "proto",
+		EncoderArgs: []string{"descriptor=testdata/proto/config.pb", "message=app.Missing"},
+		Input:       bytes.NewBufferString("name: app\n"),
+		Output:      &out,
+	})
+	require.Contains(t, err.Error(), "proto: message 'app.Missing' is not found")
+}
@@ -10,7 +10,7 @@ import (
 func TestTOML(t *testing.T) {
 	var out1 bytes.Buffer
 	var out2 bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "j",
 		Encoder: "t",
 		Input:   bytes.NewBufferString(testInput),
@@ -23,7 +23,7 @@ bsd = "asd"
 [complex]
   asd = 123.0
 `)
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "t",
 		Encoder: "j",
 		Input:   &out1,
@@ -2,11 +2,12 @@ package fc
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
 
@@ -16,10 +17,58 @@ import (
 	"github.com/juju/errors"
 )
 
+// tplHeader matches optional first line directive of
+// template file, e.g.: # gofc: delims=[[ ]]
+var tplHeader = regexp.MustCompile(`^[ \t]*(?:#|//)[ \t]*gofc:(.*)(?:\r?\n|$)`)
+
+type tplOpts struct {
+	leftDelim  string
+	rightDelim string
+}
+
+// parseTplOptions parses template options,
+// currently only 'delims=LEFT RIGHT' is supported.
+func parseTplOptions(options ...string) (opts tplOpts, err error) {
+	fields := strings.Fields(strings.Join(options, " "))
+	for i := 0; i < len(fields); i++ {
+		p := fields[i]
+		switch {
+		case strings.HasPrefix(p, "delims="):
+			opts.leftDelim = strings.TrimPrefix(p, "delims=")
+			if opts.leftDelim == "" || i+1 >= len(fields) {
+				return opts, errors.Errorf("invalid delims option, expecting 'delims=LEFT RIGHT'")
+			}
+			i++
+			opts.rightDelim = fields[i]
+		default:
+			return opts, errors.Errorf("unexpected template option '%s'", p)
+		}
+	}
+	return opts, nil
+}
+
+// parseTplHeader strips the header directive from template content.
+// Options from the header take precedence over provided options.
+func parseTplHeader(content []byte, opts tplOpts) ([]byte, tplOpts, error) {
+	m := tplHeader.FindSubmatchIndex(content)
+	if m == nil {
+		return content, opts, nil
+	}
+	headerOpts, err := parseTplOptions(string(content[m[2]:m[3]]))
+	if err != nil {
+		return nil, opts, errors.Trace(err)
+	}
+	if headerOpts.leftDelim != "" {
+		opts = headerOpts
+	}
+	return content[m[1]:], opts, nil
+}
+
 type coderTPL struct {
 	funcMap  map[string]interface{}
 	conv     *Recoder
 	importer *importer
+	schemas  schemaCache
 }
 
 func newCoderTPL(conv *Recoder, s3client s3iface.S3API) *coderTPL {
@@ -31,48 +80,60 @@ func newCoderTPL(conv *Recoder, s3client s3iface.S3API) *coderTPL {
 
 func (c *coderTPL) Initialize() error {
 	c.funcMap = sprig.TxtFuncMap()
-	c.funcMap["include"] = c.tplFuncInclude
-	c.funcMap["import"] = c.tplFuncImport
-	c.funcMap["jq"] = func(p string, in interface{}) (interface{}, error) {
-		libjq, err := jq.New()
-		if err != nil {
-			return nil, errors.Annotatef(err, "cannot initialize jq library")
-		}
-		defer libjq.Close()
-		chanIn, chanOut, chanErr := libjq.Start(strings.Replace(p, "'", "\"", -1), jq.JvArray())
-		inCopy, err := jq.JvFromInterface(in)
-		if err != nil {
-			return nil, errors.Annotatef(err, "cannot encode input data for jq")
-		}
+	return nil
+}
 
-		var res interface{}
-		for chanErr != nil && chanOut != nil {
-			select {
-			case e, ok := <-chanErr:
-				if !ok {
-					chanErr = nil
-				} else {
-					err = errors.Trace(e)
-				}
-			case o, ok := <-chanOut:
-				if !ok {
-					chanOut = nil
-				} else if res == nil {
-					res = o.ToGoVal()
-				}
-			case chanIn <- inCopy:
-				close(chanIn)
-				chanIn = nil
+// tplFuncJQ runs jq program p on in and returns the first result.
+// Running program cannot be canceled, ctx is checked before start.
+func (c *coderTPL) tplFuncJQ(ctx context.Context, p string, in interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Annotatef(err, "jq")
+	}
+	libjq, err := jq.New()
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot initialize jq library")
+	}
+	defer libjq.Close()
+	chanIn, chanOut, chanErr := libjq.Start(strings.Replace(p, "'", "\"", -1), jq.JvArray())
+	inCopy, err := jq.JvFromInterface(in)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot encode input data for jq")
+	}
+
+	var res interface{}
+	for chanErr != nil && chanOut != nil {
+		select {
+		case e, ok := <-chanErr:
+			if !ok {
+				chanErr = nil
+			} else {
+				err = errors.Trace(e)
+			}
+		case o, ok := <-chanOut:
+			if !ok {
+				chanOut = nil
+			} else if res == nil {
+				res = o.ToGoVal()
 			}
+		case chanIn <- inCopy:
+			close(chanIn)
+			chanIn = nil
 		}
-		return res, err
 	}
+	return res, err
+}
 
+// addCoderFuncs adds decode_* and encode_* functions
+// of all registered coders into funcMap.
+func (c *coderTPL) addCoderFuncs(ctx context.Context, funcMap map[string]interface{}) {
 	for n, f := range c.conv.Coders {
 		name := n
 		if _, ok := f.(Decoder); ok {
-			c.funcMap["decode_"+name] = func(in string, args ...string) (interface{}, error) {
-				data, _, err := c.conv.Decode(&Config{
+			funcMap["decode_"+name] = func(in string, args ...string) (interface{}, error) {
+				if err := ctx.Err(); err != nil {
+					return nil, errors.Annotatef(err, "error while decoding %s", name)
+				}
+				data, _, err := c.conv.DecodeContext(ctx, &Config{
 					Decoder:     name,
 					DecoderArgs: args,
 					Input:       bytes.NewBufferString(in),
@@ -84,9 +145,12 @@ func (c *coderTPL) Initialize() error {
 			}
 		}
 		if _, ok := f.(Encoder); ok {
-			c.funcMap["encode_"+name] = func(in map[string]interface{}, args ...string) (string, error) {
+			funcMap["encode_"+name] = func(in interface{}, args ...string) (string, error) {
+				if err := ctx.Err(); err != nil {
+					return "", errors.Annotatef(err, "error while encoding %s", name)
+				}
 				var buf bytes.Buffer
-				err := c.conv.Encode(&Config{
+				err := c.conv.EncodeContext(ctx, &Config{
 					Encoder:     name,
 					EncoderArgs: args,
 					Output:      &buf,
@@ -98,11 +162,13 @@ func (c *coderTPL) Initialize() error {
 			}
 		}
 	}
-	return nil
 }
 
-func (c *coderTPL) tplFuncImport(fileURL string, options ...string) (res interface{}, err error) {
-	var opts importOpts
+func (c *coderTPL) tplFuncImport(ctx context.Context, dir string, fileURL string, options ...string) (res interface{}, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, errors.Annotatef(err, "cannot import '%s'", fileURL)
+	}
+	opts := importOpts{dir: dir}
 
 	for _, p := range strings.Split(strings.Join(options, ","), ",") {
 		p = strings.TrimSpace(p)
@@ -117,30 +183,108 @@ func (c *coderTPL) tplFuncImport(fileURL string, options ...string) (res interfa
 		case "metadata":
 			opts.metadata = true
 		default:
-			return nil, errors.Errorf("unexpected import option '%s'", p)
+			if !strings.HasPrefix(p, "format=") {
+				return nil, errors.Errorf("unexpected import option '%s'", p)
+			}
+			opts.format = strings.TrimPrefix(p, "format=")
 		}
 	}
 
-	return c.importer.importURL(fileURL, opts)
+	return c.importer.importURL(ctx, fileURL, opts)
 }
 
-func (c *coderTPL) tplFuncInclude(path string, ctx interface{}, metadata ...interface{}) (string, error) {
-	buf, err := c.include(path, ctx, nil)
+// tplFuncInclude renders template at path. Arguments are string options,
+// for compatibility with earlier versions trailing argument, which is
+// not a string or not a valid option, is accepted as metadata of the
+// included template.
+func (c *coderTPL) tplFuncInclude(ctx context.Context, dir, baseDir, path string, data interface{}, args ...interface{}) (string, error) {
+	var options []string
+	var metadata interface{}
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			options = append(options, s)
+		} else if i == len(args)-1 {
+			metadata = arg
+		} else {
+			return "", errors.Errorf("tpl: cannot include '%s', option %d is not a string", path, i+1)
+		}
+	}
+	opts, err := parseTplOptions(options...)
+	if err != nil && metadata == nil && len(options) > 0 {
+		// trailing string metadata, e.g. include "page.tpl" $ "title"
+		var optsErr error
+		if opts, optsErr = parseTplOptions(options[:len(options)-1]...); optsErr == nil {
+			metadata, err = options[len(options)-1], nil
+		}
+	}
+	if err != nil {
+		return "", errors.Annotatef(err, "tpl: cannot include '%s'", path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	buf, err := c.include(ctx, path, data, metadata, opts, baseDir)
 	if err != nil {
 		return "", errors.Trace(err)
 	}
 	return buf.String(), nil
 }
 
+// tplFuncValidate validates data against schema and returns data,
+// so it can be used in pipelines. Schema is either decoded
+// document or path of the schema file, relative references
+// of which are resolved against the schema file directory.
+func (c *coderTPL) tplFuncValidate(ctx context.Context, dir string, schema interface{}, data interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Annotatef(err, "validate")
+	}
+	path := "schema.json"
+	if fileURL, ok := schema.(string); ok {
+		opts := importOpts{dir: dir}
+		var err error
+		if schema, err = c.importer.importURL(ctx, fileURL, opts); err != nil {
+			return nil, errors.Annotatef(err, "validate: cannot read schema")
+		}
+		if u, err := url.Parse(fileURL); err == nil && (u.Scheme == "" || u.Scheme == "file") {
+			path = opts.resolve(u.Host + u.Path)
+		}
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s, err := c.schemas.compile(abs, schema)
+	if err != nil {
+		return nil, errors.Annotatef(err, "validate")
+	}
+	if err = s.Validate(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return data, nil
+}
+
 func (c *coderTPL) Names() []string {
 	return []string{"tpl"}
 }
 
 func (c *coderTPL) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
-	if len(args) != 1 {
-		return errors.Trace(ArgumentError{error: "tpl: expecting one argument: template file"})
+	return c.EncodeContext(context.Background(), out, in, metadata, args)
+}
+
+func (c *coderTPL) EncodeContext(ctx context.Context, out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	return c.encodeConfig(ctx, &Config{Output: out, EncoderArgs: args}, in, metadata)
+}
+
+func (c *coderTPL) encodeConfig(ctx context.Context, config *Config, in interface{}, metadata interface{}) error {
+	out, args := config.Output, config.EncoderArgs
+	if len(args) < 1 {
+		return errors.Trace(ArgumentError{error: "tpl: expecting at least one argument: template file"})
 	}
-	buf, err := c.include(args[0], in, metadata)
+	opts, err := parseTplOptions(args[1:]...)
+	if err != nil {
+		return errors.Trace(ArgumentError{error: fmt.Sprintf("tpl: %s", err)})
+	}
+	buf, err := c.include(ctx, args[0], in, metadata, opts, config.BaseDir)
 	if err != nil {
 		return errors.Annotatef(err, "tpl: error while parsing template")
 	}
@@ -148,45 +292,71 @@ func (c *coderTPL) Encode(out io.Writer, in interface{}, metadata interface{}, a
 	return errors.Annotatef(err, "tpl: cannot write")
 }
 
-func (c *coderTPL) newFuncMap(metadata interface{}) map[string]interface{} {
+// newFuncMap creates function map for template located in dir.
+// Relative imports are resolved against baseDir, if set,
+// otherwise against the template directory.
+func (c *coderTPL) newFuncMap(ctx context.Context, metadata interface{}, dir, baseDir string) map[string]interface{} {
 	funcMap := make(map[string]interface{})
 	for k, v := range c.funcMap {
 		funcMap[k] = v
 	}
+	c.addCoderFuncs(ctx, funcMap)
+	funcMap["jq"] = func(p string, in interface{}) (interface{}, error) {
+		return c.tplFuncJQ(ctx, p, in)
+	}
 	funcMap["metadata"] = func() interface{} {
 		return metadata
 	}
+	funcMap["include"] = func(path string, data interface{}, args ...interface{}) (string, error) {
+		return c.tplFuncInclude(ctx, dir, baseDir, path, data, args...)
+	}
+	importDir := baseDir
+	if importDir == "" {
+		importDir = dir
+	}
+	funcMap["import"] = func(fileURL string, options ...string) (interface{}, error) {
+		return c.tplFuncImport(ctx, importDir, fileURL, options...)
+	}
+	funcMap["validate"] = func(schema interface{}, data interface{}) (interface{}, error) {
+		return c.tplFuncValidate(ctx, importDir, schema, data)
+	}
+	for k, v := range c.conv.funcs {
+		funcMap[k] = v
+	}
 	return funcMap
 }
 
-func (c *coderTPL) include(path string, ctx interface{}, metadata interface{}) (*bytes.Buffer, error) {
-	cwd, err := os.Getwd()
+func (c *coderTPL) include(ctx context.Context, path string, data interface{}, metadata interface{}, opts tplOpts, baseDir string) (*bytes.Buffer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Annotatef(err, "tpl: cannot render template '%s'", path)
+	}
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return nil, errors.Annotatef(err, "tpl: cannot get current directory")
+		return nil, errors.Annotatef(err, "tpl: cannot resolve template path '%s'", path)
 	}
-	defer func() {
-		if err := os.Chdir(cwd); err != nil {
-			panic(fmt.Sprintf("err: cannot chdir back to '%s', %s", cwd, err))
-		}
-	}()
 
-	dir := filepath.Dir(path)
-
-	if err = os.Chdir(dir); err != nil {
-		return nil, errors.Annotatef(err, "tpl: cannot chdir to template directory '%s'", dir)
+	c.conv.fileAccessed(absPath)
+	content, err := c.conv.readFile(absPath)
+	if err != nil {
+		return nil, errors.Annotatef(err, "tpl: cannot read template '%s'", path)
 	}
 
-	content, err := ioutil.ReadFile(filepath.Base(path))
+	content, opts, err = parseTplHeader(content, opts)
 	if err != nil {
-		return nil, errors.Annotatef(err, "tpl: cannot read template '%s'", path)
+		return nil, errors.Annotatef(err, "tpl: invalid header in template '%s'", path)
 	}
 
-	tpl, err := template.New(filepath.Join(cwd, path)).Funcs(c.newFuncMap(metadata)).Parse(string(content))
+	tpl, err := template.New(absPath).
+		Delims(opts.leftDelim, opts.rightDelim).
+		Funcs(c.newFuncMap(ctx, metadata, filepath.Dir(absPath), baseDir)).
+		Parse(string(content))
 	if err != nil {
 		return nil, errors.Annotatef(err, "tpl: cannot parse template '%s'", path)
 	}
+	// rendering stops on the next write or call of include, import,
+	// validate, jq, decode_* or encode_* after ctx is done
 	var buf bytes.Buffer
-	if err = tpl.Execute(&buf, ctx); err != nil {
+	if err = tpl.Execute(&ctxWriter{ctx: ctx, w: &buf}, data); err != nil {
 		return nil, errors.Annotatef(err, "tpl: cannot render template '%s'", path)
 	}
 	return &buf, nil
@@ -3,6 +3,8 @@ package fc
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,7 +12,7 @@ import (
 
 func TestTPL(t *testing.T) {
 	var out bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder:     "j",
 		Encoder:     "tpl",
 		EncoderArgs: []string{"./testdata/main.tpl"},
@@ -26,9 +28,50 @@ test
 test`, out.String())
 }
 
+func TestTPLIncludeMetadata(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/include_metadata.tpl"},
+		Input:       bytes.NewBufferString(`{}`),
+		Output:      &out,
+	}))
+	require.Equal(t, `{"env":"prod"}`, out.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/include_metadata.tpl"},
+		Input:       bytes.NewBufferString(`{"invalid": true}`),
+		Output:      &out,
+	})
+	require.Contains(t, err.Error(), "option 1 is not a string")
+
+	// trailing string, which is not a valid option, is metadata
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/include_metadata_string.tpl"},
+		Input:       bytes.NewBufferString(`{}`),
+		Output:      &out,
+	}))
+	require.Equal(t, `"meta"`, out.String())
+
+	err = testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/include_metadata_string.tpl"},
+		Input:       bytes.NewBufferString(`{"invalid": true}`),
+		Output:      &out,
+	})
+	require.Contains(t, err.Error(), "unexpected template option 'bogus'")
+}
+
 func TestTPLInputJSON(t *testing.T) {
 	var out bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder:     "j",
 		Encoder:     "tpl",
 		EncoderArgs: []string{"./testdata/input.tpl"},
@@ -40,7 +83,7 @@ func TestTPLInputJSON(t *testing.T) {
 
 func TestTPLOutputJSON(t *testing.T) {
 	var out bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder:     "j",
 		Encoder:     "tpl",
 		EncoderArgs: []string{"./testdata/output.tpl"},
@@ -52,7 +95,7 @@ func TestTPLOutputJSON(t *testing.T) {
 
 func TestTPLImport(t *testing.T) {
 	var out bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder:     "j",
 		Encoder:     "tpl",
 		EncoderArgs: []string{"./testdata/import.tpl"},
@@ -63,3 +106,76 @@ func TestTPLImport(t *testing.T) {
 	require.NoError(t, err)
 	require.JSONEq(t, string(expOutput), out.String())
 }
+
+func TestTPLDelims(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/delims/header.tpl"},
+		Input:       bytes.NewBufferString(`{"name": "test"}`),
+		Output:      &out,
+	}))
+	require.Equal(t, `name: test
+value: {{ literal }}
+args: test {{ x }}
+
+default: test`, out.String())
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/delims/encoder.tpl", "delims=<< >>"},
+		Input:       bytes.NewBufferString(`{"name": "test"}`),
+		Output:      &out,
+	}))
+	require.Equal(t, `encoder: test {{ x }}`, out.String())
+
+	require.Error(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/delims/encoder.tpl", "delims=<<"},
+		Input:       bytes.NewBufferString(`{"name": "test"}`),
+		Output:      &out,
+	}))
+}
+
+func TestTPLOnFileAccess(t *testing.T) {
+	var files []string
+	testRecoder.OnFileAccess = func(path string) {
+		files = append(files, path)
+	}
+	defer func() {
+		testRecoder.OnFileAccess = nil
+	}()
+
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/main.tpl"},
+		Input:       bytes.NewBufferString(`{"list": [], "map": {}}`),
+		Output:      ioutil.Discard,
+	}))
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(cwd, "testdata/main.tpl"),
+		filepath.Join(cwd, "testdata/subdir/include.tpl"),
+		filepath.Join(cwd, "testdata/include.tpl"),
+		filepath.Join(cwd, "testdata/include.tpl"),
+	}, files)
+}
+
+func TestTPLImportBaseDir(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "j",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/basedir/import.tpl"},
+		Input:       bytes.NewBufferString(testInput),
+		Output:      &out,
+		BaseDir:     "./testdata",
+	}))
+	require.JSONEq(t, `{"file": "file1"}`, out.String())
+}
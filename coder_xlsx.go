@@ -0,0 +1,265 @@
+package fc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/tealeg/xlsx"
+)
+
+// coderXLSX decodes and encodes Excel workbooks. Sheets are lists
+// of rows, each row is a map keyed by column names of the header row.
+type coderXLSX struct{}
+
+func (c *coderXLSX) Initialize() error {
+	return nil
+}
+
+func (c *coderXLSX) Names() []string {
+	return []string{"xlsx"}
+}
+
+// Decode decodes the first sheet, sheet selected with 'sheet=NAME'
+// argument or, with 'all' argument, map of all sheets keyed by name.
+func (c *coderXLSX) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	var name string
+	var all bool
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "sheet="):
+			name = strings.TrimPrefix(arg, "sheet=")
+		case arg == "all":
+			all = true
+		default:
+			return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("xlsx: invalid input argument '%s', supported arguments: 'sheet=NAME', 'all'", arg)})
+		}
+	}
+	if all && name != "" {
+		return nil, nil, errors.Trace(ArgumentError{error: "xlsx: 'sheet=NAME' and 'all' arguments cannot be used together"})
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "xlsx: cannot read input")
+	}
+	file, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot decode xlsx")
+	}
+
+	if all {
+		res := make(map[string]interface{}, len(file.Sheets))
+		for _, sheet := range file.Sheets {
+			if res[sheet.Name], err = decodeSheet(file, sheet); err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		}
+		return res, nil, nil
+	}
+	if len(file.Sheets) == 0 {
+		return nil, nil, errors.New("xlsx: workbook has no sheets")
+	}
+	sheet := file.Sheets[0]
+	if name != "" {
+		if sheet = file.Sheet[name]; sheet == nil {
+			return nil, nil, errors.Errorf("xlsx: sheet '%s' is not found", name)
+		}
+	}
+	res, err := decodeSheet(file, sheet)
+	return res, nil, errors.Trace(err)
+}
+
+// decodeSheet decodes rows following the first non-empty row, which is
+// the header. Empty cells are omitted, empty rows are skipped.
+func decodeSheet(file *xlsx.File, sheet *xlsx.Sheet) ([]interface{}, error) {
+	res := []interface{}{}
+	var header []string
+	for i, row := range sheet.Rows {
+		if row == nil {
+			continue
+		}
+		if header == nil {
+			seen := map[string]bool{}
+			keys := make([]string, len(row.Cells))
+			for j, cell := range row.Cells {
+				key := strings.TrimSpace(cell.String())
+				if key == "" {
+					continue
+				} else if seen[key] {
+					return nil, errors.Errorf("xlsx: sheet '%s': duplicate column '%s'", sheet.Name, key)
+				}
+				seen[key] = true
+				keys[j] = key
+			}
+			if len(seen) > 0 {
+				header = keys
+			}
+			continue
+		}
+
+		entry := map[string]interface{}{}
+		for j, cell := range row.Cells {
+			if j >= len(header) || header[j] == "" || cell.Value == "" {
+				continue
+			}
+			v, err := cellValue(file, cell)
+			if err != nil {
+				return nil, errors.Annotatef(err, "xlsx: sheet '%s': row %d, column '%s'", sheet.Name, i+1, header[j])
+			}
+			entry[header[j]] = v
+		}
+		if len(entry) > 0 {
+			res = append(res, entry)
+		}
+	}
+	return res, nil
+}
+
+// xlsxDecimals matches decimal places of number format, e.g. 0.00
+var xlsxDecimals = regexp.MustCompile(`\.[0#?]`)
+
+// cellValue returns typed value of the cell, cached values of formulas
+// are returned as well. Whole numbers are integers, unless number format
+// of the cell has decimal places.
+func cellValue(file *xlsx.File, cell *xlsx.Cell) (interface{}, error) {
+	switch cell.Type() {
+	case xlsx.CellTypeBool:
+		return cell.Bool(), nil
+	case xlsx.CellTypeNumeric:
+		if cell.IsTime() {
+			return cell.GetTime(file.Date1904)
+		}
+		f, err := cell.Float()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if isWholeFloat(f) && !xlsxDecimals.MatchString(cell.GetNumberFormat()) {
+			return int64(f), nil
+		}
+		return f, nil
+	case xlsx.CellTypeDate:
+		return time.Parse(time.RFC3339, cell.Value)
+	}
+	return cell.Value, nil
+}
+
+// Encode writes list of maps as a single sheet, named by 'sheet=NAME'
+// argument, or map of such lists as a sheet per key. Columns are sorted
+// by name, unless order is set with 'columns=NAME1,NAME2' argument.
+func (c *coderXLSX) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	name := "Sheet1"
+	var columns []string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "sheet="):
+			name = strings.TrimPrefix(arg, "sheet=")
+		case strings.HasPrefix(arg, "columns="):
+			columns = strings.Split(strings.TrimPrefix(arg, "columns="), ",")
+		default:
+			return errors.Trace(ArgumentError{error: fmt.Sprintf("xlsx: invalid output argument '%s', supported arguments: 'sheet=NAME', 'columns=NAME1,NAME2'", arg)})
+		}
+	}
+
+	sheets := map[string]interface{}{name: in}
+	names := []string{name}
+	if m, ok := normalizeValue(in).(map[string]interface{}); ok {
+		sheets, names = m, sortedKeys(m)
+	}
+	file := xlsx.NewFile()
+	for _, name := range names {
+		rows, ok := normalizeValue(sheets[name]).([]interface{})
+		if !ok {
+			return errors.Errorf("xlsx: cannot encode %s as sheet '%s', list of maps is expected", valueKind(sheets[name]), name)
+		}
+		sheet, err := file.AddSheet(name)
+		if err != nil {
+			return errors.Annotatef(err, "xlsx: cannot add sheet '%s'", name)
+		}
+		if err = encodeSheet(sheet, rows, columns); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		return errors.Annotatef(err, "cannot encode xlsx")
+	}
+	_, err := io.Copy(out, &buf)
+	return errors.Annotatef(err, "xlsx: cannot write")
+}
+
+// encodeSheet writes header row and rows of sheet.
+func encodeSheet(sheet *xlsx.Sheet, rows []interface{}, columns []string) error {
+	keys := map[string]bool{}
+	for _, column := range columns {
+		keys[column] = true
+	}
+	collected := map[string]interface{}{}
+	for i, row := range rows {
+		m, ok := row.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("xlsx: sheet '%s': cannot encode %s as row %d, map is expected", sheet.Name, valueKind(row), i+1)
+		}
+		for k := range m {
+			if !keys[k] {
+				collected[k] = nil
+			}
+		}
+	}
+	header := append(append([]string{}, columns...), sortedKeys(collected)...)
+
+	r := sheet.AddRow()
+	for _, key := range header {
+		r.AddCell().SetString(key)
+	}
+	// rows are numbered as in the sheet, the first row is the header
+	for i, row := range rows {
+		r = sheet.AddRow()
+		for _, key := range header {
+			cell := r.AddCell()
+			if err := setCellValue(cell, row.(map[string]interface{})[key]); err != nil {
+				return errors.Annotatef(err, "xlsx: sheet '%s': row %d, column '%s'", sheet.Name, i+2, key)
+			}
+		}
+	}
+	return nil
+}
+
+func setCellValue(cell *xlsx.Cell, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+	case string:
+		cell.SetString(v)
+	case bool:
+		cell.SetBool(v)
+	case time.Time:
+		cell.SetDateTime(v)
+	case Binary:
+		cell.SetString(v.String())
+	case map[string]interface{}, []interface{}:
+		return errors.Errorf("cannot encode %s into cell", valueKind(v))
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			cell.SetInt64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return errors.Errorf("cannot encode %d into cell, number is out of range", rv.Uint())
+			}
+			cell.SetInt64(int64(rv.Uint()))
+		case reflect.Float32, reflect.Float64:
+			cell.SetFloat(rv.Float())
+		default:
+			cell.SetString(fmt.Sprint(v))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package fc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tealeg/xlsx"
+)
+
+func TestXLSX(t *testing.T) {
+	workbook, err := ioutil.ReadFile("testdata/xlsx/quotas.xlsx")
+	require.NoError(t, err)
+
+	quotas := []interface{}{
+		map[string]interface{}{
+			"team":    "billing",
+			"cpu":     int64(8),
+			"memory":  "16Gi",
+			"enabled": true,
+			"review":  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		// empty cells are omitted, empty rows are skipped
+		map[string]interface{}{"team": "search", "cpu": 2.5, "enabled": false},
+		// cached value of formula
+		map[string]interface{}{"team": "total", "cpu": 10.5},
+	}
+	owners := []interface{}{
+		map[string]interface{}{"team": "billing", "owner": "alice"},
+	}
+
+	data, _, err := testRecoder.Decode(&Config{Decoder: "xlsx", Input: bytes.NewBuffer(workbook)})
+	require.NoError(t, err)
+	require.Equal(t, quotas, data)
+
+	data, _, err = testRecoder.Decode(&Config{Decoder: "xlsx", DecoderArgs: []string{"sheet=owners"}, Input: bytes.NewBuffer(workbook)})
+	require.NoError(t, err)
+	require.Equal(t, owners, data)
+
+	all, _, err := testRecoder.Decode(&Config{Decoder: "xlsx", DecoderArgs: []string{"all"}, Input: bytes.NewBuffer(workbook)})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"quotas": quotas, "owners": owners}, all)
+
+	_, _, err = testRecoder.Decode(&Config{Decoder: "xlsx", DecoderArgs: []string{"sheet=missing"}, Input: bytes.NewBuffer(workbook)})
+	require.Contains(t, err.Error(), "xlsx: sheet 'missing' is not found")
+	_, _, err = testRecoder.Decode(&Config{Decoder: "xlsx", DecoderArgs: []string{"sheet=owners", "all"}, Input: bytes.NewBuffer(workbook)})
+	require.Contains(t, err.Error(), "xlsx: 'sheet=NAME' and 'all' arguments cannot be used together")
+
+	// all sheets are written back
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Encode(&Config{Encoder: "xlsx", Output: &out}, all, nil))
+	res, _, err := testRecoder.Decode(&Config{Decoder: "xlsx", DecoderArgs: []string{"all"}, Input: &out})
+	require.NoError(t, err)
+	require.Equal(t, all, res)
+
+	out.Reset()
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		Encoder:     "xlsx",
+		EncoderArgs: []string{"sheet=limits", "columns=team,cpu"},
+		Input:       bytes.NewBufferString("[{team: billing, cpu: 8, memory: 16Gi}, {team: search, gpu: 1}]"),
+		Output:      &out,
+	}))
+	file, err := xlsx.OpenBinary(out.Bytes())
+	require.NoError(t, err)
+	rows, err := file.ToSlice()
+	require.NoError(t, err)
+	require.Equal(t, [][][]string{{
+		{"team", "cpu", "gpu", "memory"},
+		{"billing", "8", "", "16Gi"},
+		{"search", "", "1", ""},
+	}}, rows)
+	require.Equal(t, "limits", file.Sheets[0].Name)
+
+	err = testRecoder.Run(&Config{
+		Decoder: "yaml",
+		Encoder: "xlsx",
+		Input:   bytes.NewBufferString("[{team: billing, tags: [a]}]"),
+		Output:  &out,
+	})
+	require.Contains(t, err.Error(), "xlsx: sheet 'Sheet1': row 2, column 'tags': cannot encode list into cell")
+}
+
+func TestXLSXNumberFormat(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	require.NoError(t, err)
+	row := sheet.AddRow()
+	for _, key := range []string{"general", "integer", "decimals", "fraction"} {
+		row.AddCell().SetString(key)
+	}
+	row = sheet.AddRow()
+	row.AddCell().SetFloat(8)
+	row.AddCell().SetFloatWithFormat(1200, "#,##0")
+	row.AddCell().SetFloatWithFormat(8, "0.00")
+	row.AddCell().SetFloat(2.5)
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+
+	data, _, err := testRecoder.Decode(&Config{Decoder: "xlsx", Input: &buf})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"general": int64(8), "integer": int64(1200), "decimals": float64(8), "fraction": 2.5},
+	}, data)
+}
+
+func TestXLSXSetCellUint(t *testing.T) {
+	sheet, err := xlsx.NewFile().AddSheet("Sheet1")
+	require.NoError(t, err)
+	cell := sheet.AddRow().AddCell()
+
+	require.NoError(t, setCellValue(cell, uint8(200)))
+	require.Equal(t, "200", cell.Value)
+	require.NoError(t, setCellValue(cell, uint64(math.MaxInt64)))
+	require.Equal(t, "9223372036854775807", cell.Value)
+	require.Contains(t, setCellValue(cell, uint64(math.MaxUint64)).Error(), "cannot encode 18446744073709551615 into cell, number is out of range")
+}
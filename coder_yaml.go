@@ -63,8 +63,10 @@ func (c coderYAML) normalize(in reflect.Value) reflect.Value {
 }
 
 func (c *coderYAML) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
-	if len(args) > 0 {
-		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("YAML: unexpected input argument '%s', no arguments expected", args[0])})
+	if len(args) == 1 && args[0] == "multi" {
+		return c.decodeMulti(in)
+	} else if len(args) > 0 {
+		return nil, nil, errors.Trace(ArgumentError{error: fmt.Sprintf("YAML: unexpected input argument '%s', supported arguments: 'multi'", args[0])})
 	}
 
 	data, err := ioutil.ReadAll(in)
@@ -76,11 +78,35 @@ func (c *coderYAML) Decode(in io.Reader, args []string) (interface{}, interface{
 	var out interface{}
 	if err = yaml.Unmarshal(data, &out); err != nil {
 		return nil, nil, err
+	} else if out == nil {
+		// empty document
+		return nil, nil, nil
 	}
 
 	return c.normalize(reflect.Indirect(reflect.ValueOf(out))).Interface(), nil, nil
 }
 
+// decodeMulti decodes all documents of multi-document
+// stream into list of documents, empty documents are skipped.
+func (c *coderYAML) decodeMulti(in io.Reader) (interface{}, interface{}, error) {
+	docs := []interface{}{}
+	decoder := yaml.NewDecoder(in)
+	for {
+		var out interface{}
+		err := decoder.Decode(&out)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, errors.Annotatef(err, "YAML: cannot decode document %d", len(docs)+1)
+		}
+		if out == nil {
+			continue
+		}
+		docs = append(docs, c.normalize(reflect.Indirect(reflect.ValueOf(out))).Interface())
+	}
+	return docs, nil, nil
+}
+
 func (c *coderYAML) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
 	if len(args) > 0 {
 		return errors.Trace(ArgumentError{error: fmt.Sprintf("YAML: unexpected output argument '%s', no arguments expected", args[0])})
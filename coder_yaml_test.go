@@ -10,7 +10,7 @@ import (
 func TestYAML(t *testing.T) {
 	var out1 bytes.Buffer
 	var out2 bytes.Buffer
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "j",
 		Encoder: "y",
 		Input:   bytes.NewBufferString(testInput),
@@ -25,7 +25,7 @@ bsd: asd
 complex:
   asd: 123
 `)
-	require.NoError(t, DefaultRecoder.Run(&Config{
+	require.NoError(t, testRecoder.Run(&Config{
 		Decoder: "y",
 		Encoder: "j",
 		Input:   &out1,
@@ -33,3 +33,15 @@ complex:
 	}))
 	require.JSONEq(t, testInput, out2.String())
 }
+
+func TestYAMLMulti(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "yaml",
+		DecoderArgs: []string{"multi"},
+		Encoder:     "json",
+		Input:       bytes.NewBufferString("a: 1\n---\nb: [2]\n---\n"),
+		Output:      &out,
+	}))
+	require.JSONEq(t, `[{"a": 1}, {"b": [2]}]`, out.String())
+}
@@ -0,0 +1,84 @@
+package fc
+
+import (
+	"context"
+	"io"
+
+	"github.com/juju/errors"
+)
+
+// AdaptDecoder returns ContextDecoder for d. Decoders, which don't
+// implement ContextDecoder, are wrapped, so that reading of input
+// fails after ctx is done.
+func AdaptDecoder(d Decoder) ContextDecoder {
+	if cd, ok := d.(ContextDecoder); ok {
+		return cd
+	}
+	return legacyDecoder{d}
+}
+
+// AdaptEncoder returns ContextEncoder for e. Encoders, which don't
+// implement ContextEncoder, are wrapped, so that writing of output
+// fails after ctx is done.
+func AdaptEncoder(e Encoder) ContextEncoder {
+	if ce, ok := e.(ContextEncoder); ok {
+		return ce
+	}
+	return legacyEncoder{e}
+}
+
+type legacyDecoder struct {
+	Decoder
+}
+
+func (d legacyDecoder) DecodeContext(ctx context.Context, reader io.Reader, args []string) (interface{}, interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	data, metadata, err := d.Decode(&ctxReader{ctx: ctx, r: reader}, args)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, nil, errors.Trace(ctxErr)
+	}
+	return data, metadata, err
+}
+
+type legacyEncoder struct {
+	Encoder
+}
+
+func (e legacyEncoder) EncodeContext(ctx context.Context, writer io.Writer, in interface{}, metadata interface{}, args []string) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	err := e.Encode(&ctxWriter{ctx: ctx, w: writer}, in, metadata, args)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errors.Trace(ctxErr)
+	}
+	return err
+}
+
+// ctxReader is a reader, which fails after ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ctxWriter is a writer, which fails after ctx is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w *ctxWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
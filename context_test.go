@@ -0,0 +1,97 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	err := testRecoder.RunContext(ctx, &Config{
+		Decoder: "json",
+		Encoder: "yaml",
+		Input:   bytes.NewBufferString(testInput),
+		Output:  &out,
+	})
+	require.Equal(t, context.Canceled, errors.Cause(err))
+	require.Empty(t, out.String())
+}
+
+func TestRunContextImportTimeout(t *testing.T) {
+	r, err := NewRecoder(
+		WithBuiltinCoders(),
+		WithImporter("slow", func(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	err = r.RunContext(ctx, &Config{
+		Decoder:     "json",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/context/slow.tpl"},
+		Input:       bytes.NewBufferString(testInput),
+		Output:      &out,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
+
+func TestAdaptDecoder(t *testing.T) {
+	require.IsType(t, legacyDecoder{}, AdaptDecoder(&coderJSON{}))
+	require.IsType(t, &coderTPL{}, AdaptEncoder(testRecoder.Encoders["tpl"]))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := AdaptDecoder(&coderJSON{}).DecodeContext(ctx, bytes.NewBufferString(testInput), nil)
+	require.Equal(t, context.Canceled, errors.Cause(err))
+}
+
+func TestTPLFuncsCanceled(t *testing.T) {
+	for _, call := range []string{
+		`include "/t/empty.tpl" .`,
+		`import "/t/vars.yml"`,
+		`validate (dict) .`,
+		`jq ".a" .`,
+		`decode_json "{}"`,
+		`encode_json .`,
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		r, err := NewRecoder(
+			WithBuiltinCoders(),
+			WithFileSystem(memFileSystem{
+				// template calls function after cancellation without writing output
+				"/t/main.tpl":  "{{ $c := cancel }}{{ $v := " + call + " }}",
+				"/t/empty.tpl": "",
+				"/t/vars.yml":  "a: 1\n",
+			}),
+			WithFuncs(map[string]interface{}{
+				"cancel": func() string { cancel(); return "" },
+			}),
+		)
+		require.NoError(t, err)
+		err = r.RunContext(ctx, &Config{
+			Decoder:     "json",
+			Encoder:     "tpl",
+			EncoderArgs: []string{"/t/main.tpl"},
+			Input:       bytes.NewBufferString(`{"a": 1}`),
+			Output:      &bytes.Buffer{},
+		})
+		require.Error(t, err, call)
+		require.Contains(t, err.Error(), context.Canceled.Error(), call)
+	}
+}
@@ -0,0 +1,368 @@
+package fc
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DecodeError is returned by DecodeInto, when
+// decoded data cannot be assigned to the target.
+type DecodeError struct {
+	// Path to the value, elements are either
+	// string map keys or int list indexes.
+	Path []interface{}
+
+	Message string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", Change{Path: e.Path}.PathString(), e.Message)
+}
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeTime            = reflect.TypeOf(time.Time{})
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DecodeInto decodes config.Input using config.Decoder and stores
+// the result in the value pointed to by out.
+//
+// Struct fields are matched by name from `fc`, `json`, `yaml` or `toml`
+// tag, in that order, or by case-insensitive field name. Tag option
+// "required" makes the key mandatory and `default` tag sets the value,
+// which is parsed as YAML, for missing keys. Durations are parsed from
+// strings like "1m30s", string values are assigned to types implementing
+// encoding.TextUnmarshaler. With config.Strict keys without matching
+// fields are reported as errors.
+func (r *Recoder) DecodeInto(config *Config, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("cannot decode into non-pointer %T", out)
+	}
+	data, _, err := r.decodeValidated(context.Background(), config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	d := &structDecoder{strict: config.Strict}
+	return errors.Trace(d.decode(nil, normalizeValue(data), rv.Elem()))
+}
+
+type structDecoder struct {
+	strict bool
+}
+
+func (d *structDecoder) errorf(path []interface{}, format string, args ...interface{}) error {
+	return &DecodeError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func (d *structDecoder) decode(path []interface{}, in interface{}, out reflect.Value) error {
+	if in == nil {
+		out.Set(reflect.Zero(out.Type()))
+		return nil
+	}
+	if out.Kind() == reflect.Ptr {
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		return d.decode(path, in, out.Elem())
+	}
+
+	switch out.Type() {
+	case typeDuration:
+		switch v := in.(type) {
+		case string:
+			dur, err := time.ParseDuration(v)
+			if err != nil {
+				return d.errorf(path, "invalid duration '%s'", v)
+			}
+			out.SetInt(int64(dur))
+			return nil
+		}
+		// numbers are ambiguous, e.g. seconds or nanoseconds
+		return d.errorf(path, "cannot decode %s into duration, string like '1m30s' is expected", valueKind(in))
+	case typeTime:
+		switch v := in.(type) {
+		case time.Time:
+			out.Set(reflect.ValueOf(v))
+			return nil
+		case string:
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return d.errorf(path, "invalid time '%s', RFC 3339 format is expected", v)
+			}
+			out.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return d.errorf(path, "cannot decode %s into time", valueKind(in))
+	}
+	if s, ok := in.(string); ok && reflect.PtrTo(out.Type()).Implements(typeTextUnmarshaler) {
+		if err := out.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return d.errorf(path, "%s", err)
+		}
+		return nil
+	}
+
+	switch out.Kind() {
+	case reflect.Interface:
+		if out.NumMethod() == 0 {
+			out.Set(reflect.ValueOf(in))
+			return nil
+		}
+	case reflect.Bool:
+		if v, ok := in.(bool); ok {
+			out.SetBool(v)
+			return nil
+		}
+	case reflect.String:
+		if v, ok := in.(string); ok {
+			out.SetString(v)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, number, ok := toInt64(in); number {
+			if !ok || out.OverflowInt(v) {
+				return d.errorf(path, "value %v overflows %s", in, out.Type())
+			}
+			out.SetInt(v)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, number, ok := toUint64(in); number {
+			if !ok || out.OverflowUint(v) {
+				return d.errorf(path, "value %v overflows %s", in, out.Type())
+			}
+			out.SetUint(v)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat(in); ok {
+			out.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		if b, ok := in.(Binary); ok && out.Type().Elem().Kind() == reflect.Uint8 {
+			out.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		if l, ok := in.([]interface{}); ok {
+			res := reflect.MakeSlice(out.Type(), len(l), len(l))
+			for i, e := range l {
+				if err := d.decode(appendPath(path, i), e, res.Index(i)); err != nil {
+					return err
+				}
+			}
+			out.Set(res)
+			return nil
+		}
+	case reflect.Array:
+		if l, ok := in.([]interface{}); ok {
+			if len(l) != out.Len() {
+				return d.errorf(path, "expected list of %d elements, got %d", out.Len(), len(l))
+			}
+			for i, e := range l {
+				if err := d.decode(appendPath(path, i), e, out.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := in.(map[string]interface{}); ok && out.Type().Key().Kind() == reflect.String {
+			res := reflect.MakeMapWithSize(out.Type(), len(m))
+			for k, e := range m {
+				v := reflect.New(out.Type().Elem()).Elem()
+				if err := d.decode(appendPath(path, k), e, v); err != nil {
+					return err
+				}
+				res.SetMapIndex(reflect.ValueOf(k).Convert(out.Type().Key()), v)
+			}
+			out.Set(res)
+			return nil
+		}
+	case reflect.Struct:
+		if m, ok := in.(map[string]interface{}); ok {
+			return d.decodeStruct(path, m, out)
+		}
+	}
+	return d.errorf(path, "cannot decode %s into %s", valueKind(in), out.Type())
+}
+
+// structField is a struct field with its key name.
+type structField struct {
+	key      string
+	index    []int
+	required bool
+	def      string
+	hasDef   bool
+}
+
+// structFields returns fields of struct type t,
+// fields of embedded structs are included.
+func structFields(t reflect.Type, index []int) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && fieldTag(f) == "" {
+			fields = append(fields, structFields(f.Type, idx)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := fieldTag(f)
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		field := structField{key: parts[0], index: idx}
+		if field.key == "" {
+			field.key = f.Name
+		}
+		for _, opt := range parts[1:] {
+			if opt == "required" {
+				field.required = true
+			}
+		}
+		field.def, field.hasDef = f.Tag.Lookup("default")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func fieldTag(f reflect.StructField) string {
+	for _, name := range []string{"fc", "json", "yaml", "toml"} {
+		if tag, ok := f.Tag.Lookup(name); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+func (d *structDecoder) decodeStruct(path []interface{}, in map[string]interface{}, out reflect.Value) error {
+	used := make(map[string]bool, len(in))
+	for _, field := range structFields(out.Type(), nil) {
+		key, ok := field.key, false
+		if _, ok = in[key]; !ok {
+			// fall back to case-insensitive match
+			for k := range in {
+				if strings.EqualFold(k, key) && !used[k] {
+					key, ok = k, true
+					break
+				}
+			}
+		}
+		p := appendPath(path, key)
+		v := out.FieldByIndex(field.index)
+		switch {
+		case ok:
+			used[key] = true
+			if err := d.decode(p, in[key], v); err != nil {
+				return err
+			}
+		case field.required:
+			return d.errorf(p, "required field is missing")
+		case field.hasDef:
+			var def interface{} = field.def
+			if indirectKind(v.Type()) != reflect.String {
+				if err := yaml.Unmarshal([]byte(field.def), &def); err != nil {
+					return d.errorf(p, "invalid default value '%s'", field.def)
+				}
+			}
+			if err := d.decode(p, normalizeValue(def), v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.strict {
+		var unknown []string
+		for k := range in {
+			if !used[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return d.errorf(appendPath(path, unknown[0]), "unknown field")
+		}
+	}
+	return nil
+}
+
+func indirectKind(t reflect.Type) reflect.Kind {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind()
+}
+
+// valueKind returns name of decoded value type for error messages.
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case Binary:
+		return "binary"
+	case bool:
+		return "bool"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// toInt64 converts number into int64, integers are converted directly,
+// so that large values keep precision. number is false if in is not
+// a number, ok is false if the number is not whole or out of range.
+func toInt64(in interface{}) (v int64, number, ok bool) {
+	rv := reflect.ValueOf(in)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true, rv.Uint() <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		// range is checked before conversion, as converting
+		// out of range float is implementation-defined
+		f := rv.Float()
+		if f != math.Trunc(f) || f < -(1<<63) || f >= 1<<63 {
+			return 0, true, false
+		}
+		return int64(f), true, true
+	}
+	return 0, false, false
+}
+
+// toUint64 converts number into uint64, see toInt64.
+func toUint64(in interface{}) (v uint64, number, ok bool) {
+	rv := reflect.ValueOf(in)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(rv.Int()), true, rv.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), true, true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < 0 || f >= 1<<64 {
+			return 0, true, false
+		}
+		return uint64(f), true, true
+	}
+	return 0, false, false
+}
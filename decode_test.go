@@ -0,0 +1,117 @@
+package fc
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testServer struct {
+	Name    string        `yaml:"name" fc:"name,required"`
+	Port    int           `json:"port" default:"8080"`
+	Timeout time.Duration `default:"30s"`
+	Version string        `default:"1"`
+	Listen  net.IP
+	Tags    []string
+	Limits  map[string]float64
+	TLS     *struct {
+		Cert string
+	}
+	testEmbedded
+}
+
+type testEmbedded struct {
+	Debug bool `toml:"debug"`
+}
+
+func TestDecodeInto(t *testing.T) {
+	for _, test := range []struct {
+		decoder string
+		input   string
+	}{
+		{"yaml", "name: web\ntimeout: 1m\nlisten: 127.0.0.1\ntags: [a, b]\nlimits: {cpu: 1}\ntls: {cert: x.pem}\ndebug: true\n"},
+		{"json", `{"name": "web", "Timeout": "1m", "listen": "127.0.0.1", "tags": ["a", "b"], "limits": {"cpu": 1}, "tls": {"cert": "x.pem"}, "debug": true}`},
+		{"toml", "name = \"web\"\ntimeout = \"1m\"\nlisten = \"127.0.0.1\"\ntags = [\"a\", \"b\"]\ndebug = true\n[limits]\ncpu = 1\n[tls]\ncert = \"x.pem\"\n"},
+	} {
+		var s testServer
+		require.NoError(t, testRecoder.DecodeInto(&Config{
+			Decoder: test.decoder,
+			Input:   bytes.NewBufferString(test.input),
+			Strict:  true,
+		}, &s), test.decoder)
+		require.Equal(t, "web", s.Name)
+		require.Equal(t, 8080, s.Port)
+		require.Equal(t, time.Minute, s.Timeout)
+		require.Equal(t, "1", s.Version)
+		require.Equal(t, "127.0.0.1", s.Listen.String())
+		require.Equal(t, []string{"a", "b"}, s.Tags)
+		require.Equal(t, map[string]float64{"cpu": 1}, s.Limits)
+		require.Equal(t, "x.pem", s.TLS.Cert)
+		require.True(t, s.Debug)
+	}
+}
+
+func TestDecodeIntoErrors(t *testing.T) {
+	for input, msg := range map[string]string{
+		`{"port": 1}`:                         ".name: required field is missing",
+		`{"name": "a", "port": "80"}`:         ".port: cannot decode string into int",
+		`{"name": "a", "port": 1.5}`:          ".port: value 1.5 overflows int",
+		`{"name": "a", "timeout": 30}`:        ".timeout: cannot decode number into duration, string like '1m30s' is expected",
+		`{"name": "a", "tags": ["a", 1]}`:     ".tags[1]: cannot decode number into string",
+		`{"name": "a", "tls": {"key": "x"}}`:  ".tls.key: unknown field",
+		`{"name": "a", "listen": "1.2.3.x"}`:  ".listen: invalid IP address: 1.2.3.x",
+		`{"name": "a", "limits": {"cpu": 1}}`: "",
+	} {
+		var s testServer
+		err := testRecoder.DecodeInto(&Config{
+			Decoder: "json",
+			Input:   bytes.NewBufferString(input),
+			Strict:  true,
+		}, &s)
+		if msg == "" {
+			require.NoError(t, err, input)
+			continue
+		}
+		require.Error(t, err, input)
+		require.IsType(t, &DecodeError{}, errors.Cause(err), input)
+		require.Equal(t, msg, errors.Cause(err).Error(), input)
+	}
+
+	var s testServer
+	require.NoError(t, testRecoder.DecodeInto(&Config{
+		Decoder: "json",
+		Input:   bytes.NewBufferString(`{"name": "a", "unknown": 1}`),
+	}, &s))
+}
+
+func TestDecodeIntoIntegers(t *testing.T) {
+	type numbers struct {
+		Int   int64  `yaml:"int"`
+		Uint  uint64 `yaml:"uint"`
+		Small int8   `yaml:"small"`
+	}
+	var n numbers
+	require.NoError(t, testRecoder.DecodeInto(&Config{
+		Decoder: "yaml",
+		Input:   bytes.NewBufferString("int: 9007199254740993\nuint: 18446744073709551615\nsmall: 2.0"),
+	}, &n))
+	require.Equal(t, numbers{Int: 9007199254740993, Uint: 18446744073709551615, Small: 2}, n)
+
+	for input, msg := range map[string]string{
+		"int: 18446744073709551615": ".int: value 18446744073709551615 overflows int64",
+		"int: 1.0e19":               ".int: value 1e+19 overflows int64",
+		"int: -1.0e19":              ".int: value -1e+19 overflows int64",
+		"int: .nan":                 ".int: value NaN overflows int64",
+		"uint: -1":                  ".uint: value -1 overflows uint64",
+		"uint: 2.0e20":              ".uint: value 2e+20 overflows uint64",
+		"small: 128":                ".small: value 128 overflows int8",
+	} {
+		err := testRecoder.DecodeInto(&Config{Decoder: "yaml", Input: bytes.NewBufferString(input)}, &n)
+		require.Error(t, err, input)
+		require.Equal(t, msg, errors.Cause(err).Error(), input)
+	}
+}
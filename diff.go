@@ -0,0 +1,220 @@
+package fc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Diff operations.
+const (
+	DiffAdd     = "add"
+	DiffRemove  = "remove"
+	DiffReplace = "replace"
+)
+
+// Change is a single difference between two documents.
+type Change struct {
+	// Op is one of DiffAdd, DiffRemove or DiffReplace.
+	Op string
+
+	// Path to changed value, elements are either
+	// string map keys or int list indexes.
+	Path []interface{}
+
+	From interface{}
+	To   interface{}
+}
+
+var diffIdentKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// PathString returns jq-style path, e.g.: .spec.containers[0].image
+func (c Change) PathString() string {
+	if len(c.Path) == 0 {
+		return "."
+	}
+	var buf strings.Builder
+	for _, p := range c.Path {
+		switch v := p.(type) {
+		case int:
+			fmt.Fprintf(&buf, "[%d]", v)
+		case string:
+			if diffIdentKey.MatchString(v) {
+				buf.WriteString("." + v)
+			} else {
+				js, _ := json.Marshal(v)
+				fmt.Fprintf(&buf, "[%s]", js)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Pointer returns JSON Pointer (RFC 6901) of the changed value.
+func (c Change) Pointer() string {
+	var buf strings.Builder
+	for _, p := range c.Path {
+		buf.WriteString("/")
+		switch v := p.(type) {
+		case int:
+			fmt.Fprintf(&buf, "%d", v)
+		case string:
+			buf.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(v))
+		}
+	}
+	return buf.String()
+}
+
+func (c Change) String() string {
+	switch c.Op {
+	case DiffAdd:
+		return fmt.Sprintf("+ %s: %s", c.PathString(), diffValue(c.To))
+	case DiffRemove:
+		return fmt.Sprintf("- %s: %s", c.PathString(), diffValue(c.From))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.PathString(), diffValue(c.From), diffValue(c.To))
+}
+
+func diffValue(v interface{}) string {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(js)
+}
+
+// JSONPatch converts changes into JSON Patch (RFC 6902) operations.
+func JSONPatch(changes []Change) []map[string]interface{} {
+	patch := make([]map[string]interface{}, 0, len(changes))
+	for _, c := range changes {
+		op := map[string]interface{}{
+			"op":   c.Op,
+			"path": c.Pointer(),
+		}
+		if c.Op != DiffRemove {
+			op["value"] = c.To
+		}
+		patch = append(patch, op)
+	}
+	return patch
+}
+
+// Diff structurally compares two decoded documents. Map key order
+// is ignored and numbers are compared by value, so documents
+// decoded from different formats can be compared.
+func Diff(a, b interface{}) []Change {
+	return diffValues(nil, normalizeValue(a), normalizeValue(b), nil)
+}
+
+func diffValues(path []interface{}, a, b interface{}, changes []Change) []Change {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := appendPath(path, k)
+			ae, aok := av[k]
+			be, bok := bv[k]
+			switch {
+			case !aok:
+				changes = append(changes, Change{Op: DiffAdd, Path: p, To: be})
+			case !bok:
+				changes = append(changes, Change{Op: DiffRemove, Path: p, From: ae})
+			default:
+				changes = diffValues(p, ae, be, changes)
+			}
+		}
+		return changes
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			changes = diffValues(appendPath(path, i), av[i], bv[i], changes)
+		}
+		for i := len(av); i < len(bv); i++ {
+			changes = append(changes, Change{Op: DiffAdd, Path: appendPath(path, i), To: bv[i]})
+		}
+		// remove from the end, so that indexes of JSON Patch stay valid
+		for i := len(av) - 1; i >= len(bv); i-- {
+			changes = append(changes, Change{Op: DiffRemove, Path: appendPath(path, i), From: av[i]})
+		}
+		return changes
+	default:
+		if equalScalars(a, b) {
+			return changes
+		}
+	}
+	return append(changes, Change{Op: DiffReplace, Path: path, From: a, To: b})
+}
+
+func appendPath(path []interface{}, e interface{}) []interface{} {
+	res := make([]interface{}, len(path), len(path)+1)
+	copy(res, path)
+	return append(res, e)
+}
+
+func equalScalars(a, b interface{}) bool {
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		return af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return f, !math.IsNaN(f)
+	}
+	return 0, false
+}
+
+// normalizeValue converts maps and slices of any type, e.g.
+// []map[string]interface{} produced by TOML decoder, into
+// map[string]interface{} and []interface{} recursively.
+func normalizeValue(in interface{}) interface{} {
+	rv := reflect.ValueOf(in)
+	switch rv.Kind() {
+	case reflect.Map:
+		res := make(map[string]interface{}, rv.Len())
+		for _, k := range rv.MapKeys() {
+			res[fmt.Sprint(k.Interface())] = normalizeValue(rv.MapIndex(k).Interface())
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return in
+		}
+		res := make([]interface{}, rv.Len())
+		for i := range res {
+			res[i] = normalizeValue(rv.Index(i).Interface())
+		}
+		return res
+	}
+	return in
+}
@@ -0,0 +1,75 @@
+package fc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a, _, err := testRecoder.Decode(&Config{
+		Decoder: "yaml",
+		Input: bytes.NewBufferString(`
+spec:
+  replicas: 2
+  ports: [80, 443, 8080]
+  labels:
+    app: web
+    "app.kubernetes.io/name": web
+debug: true
+`),
+	})
+	require.NoError(t, err)
+	b, _, err := testRecoder.Decode(&Config{
+		Decoder: "json",
+		Input: bytes.NewBufferString(`{
+  "spec": {
+    "labels": {"app.kubernetes.io/name": "api", "app": "web"},
+    "ports": [80, 443],
+    "replicas": 3,
+    "image": "api:v2"
+  },
+  "debug": true
+}`),
+	})
+	require.NoError(t, err)
+
+	require.Empty(t, Diff(a, a))
+
+	changes := Diff(a, b)
+	var res []string
+	for _, c := range changes {
+		res = append(res, c.String())
+	}
+	require.Equal(t, []string{
+		`+ .spec.image: "api:v2"`,
+		`~ .spec.labels["app.kubernetes.io/name"]: "web" -> "api"`,
+		`- .spec.ports[2]: 8080`,
+		`~ .spec.replicas: 2 -> 3`,
+	}, res)
+
+	patch, err := json.Marshal(JSONPatch(changes))
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"op": "add", "path": "/spec/image", "value": "api:v2"},
+		{"op": "replace", "path": "/spec/labels/app.kubernetes.io~1name", "value": "api"},
+		{"op": "remove", "path": "/spec/ports/2"},
+		{"op": "replace", "path": "/spec/replicas", "value": 3}
+	]`, string(patch))
+}
+
+func TestDiffTOML(t *testing.T) {
+	a, _, err := testRecoder.Decode(&Config{
+		Decoder: "toml",
+		Input:   bytes.NewBufferString("[[servers]]\nname = \"a\"\nport = 80\n"),
+	})
+	require.NoError(t, err)
+	b, _, err := testRecoder.Decode(&Config{
+		Decoder: "yaml",
+		Input:   bytes.NewBufferString("servers:\n  - name: a\n    port: 80\n"),
+	})
+	require.NoError(t, err)
+	require.Empty(t, Diff(a, b))
+}
@@ -1,11 +1,15 @@
 package fc
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/juju/errors"
 )
 
@@ -19,6 +23,22 @@ type Config struct {
 
 	Input  io.Reader
 	Output io.Writer
+
+	// BaseDir is the base directory of relative imports.
+	// If empty, imports are relative to the template file.
+	BaseDir string
+
+	// Schema, if set, is used to validate decoded
+	// data before it's passed to the encoder.
+	Schema *Schema
+
+	// CUE, if set, is unified with decoded data before
+	// validation, default values of the schema are filled in.
+	CUE *CUESchema
+
+	// Strict makes DecodeInto fail on keys,
+	// which have no matching struct field.
+	Strict bool
 }
 
 // Recoder represent set of encoders
@@ -30,6 +50,19 @@ type Recoder struct {
 	Decoders map[string]Decoder
 
 	Coders map[string]Coder
+
+	// OnFileAccess, if set, is called with absolute path of
+	// every local file or file pattern read by coders,
+	// e.g. templates, includes and imports.
+	OnFileAccess func(path string)
+
+	funcs     map[string]interface{}
+	importers map[string]ImportFunc
+	fs        FileSystem
+
+	s3     s3iface.S3API
+	s3Once sync.Once
+	s3Err  error
 }
 
 // Register new converter
@@ -45,6 +78,17 @@ func (r *Recoder) Register(c Coder) {
 	}
 }
 
+// fileAccessed notifies OnFileAccess callback about accessed file.
+func (r *Recoder) fileAccessed(path string) {
+	if r.OnFileAccess == nil {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	r.OnFileAccess(path)
+}
+
 // Initialize converters after registration
 func (r *Recoder) Initialize() error {
 	for _, c := range r.Coders {
@@ -57,21 +101,62 @@ func (r *Recoder) Initialize() error {
 
 // Run converter with provided configuration
 func (r *Recoder) Run(config *Config) error {
-	data, metadata, err := r.Decode(config)
+	return r.RunContext(context.Background(), config)
+}
+
+// RunContext runs converter with provided configuration,
+// conversion is aborted, when ctx is done.
+func (r *Recoder) RunContext(ctx context.Context, config *Config) error {
+	data, metadata, err := r.decodeValidated(ctx, config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(r.EncodeContext(ctx, config, data, metadata), "cannot run output conveter")
+}
+
+// decodeValidated decodes config.Input and checks data against
+// config.CUE and config.Schema. Violations in YAML input are located
+// in the source, so that they are reported with line numbers.
+func (r *Recoder) decodeValidated(ctx context.Context, config *Config) (interface{}, interface{}, error) {
+	_, yaml := r.Decoders[config.Decoder].(*coderYAML)
+	yaml = yaml && (config.CUE != nil || config.Schema != nil)
+	var src bytes.Buffer
+	in := config
+	if yaml {
+		c := *config
+		c.Input = io.TeeReader(config.Input, &src)
+		in = &c
+	}
+	data, metadata, err := r.DecodeContext(ctx, in)
 	if err != nil {
-		return errors.Annotatef(err, "cannot run input converter")
+		return nil, nil, errors.Annotatef(err, "cannot run input converter")
+	}
+	if config.CUE != nil {
+		data, err = config.CUE.Unify(data)
+	}
+	if err == nil && config.Schema != nil {
+		err = config.Schema.Validate(data)
+	}
+	if verr, ok := errors.Cause(err).(*ValidationError); ok && yaml {
+		verr.LocateYAML(src.Bytes(), len(config.DecoderArgs) == 1 && config.DecoderArgs[0] == "multi")
 	}
-	return errors.Annotatef(r.Encode(config, data, metadata), "cannot run output conveter")
+	return data, metadata, errors.Trace(err)
 }
 
 // Decode function decodes data stream from config.Input
 // using config.Decoder.
 func (r *Recoder) Decode(config *Config) (interface{}, interface{}, error) {
+	return r.DecodeContext(context.Background(), config)
+}
+
+// DecodeContext is the same as Decode, but decoding
+// is aborted, when ctx is done.
+func (r *Recoder) DecodeContext(ctx context.Context, config *Config) (interface{}, interface{}, error) {
 	input, ok := r.Decoders[config.Decoder]
 	if !ok {
 		return nil, nil, errors.Errorf("unknown decoder '%s'", config.Decoder)
 	}
-	data, metadata, err := input.Decode(config.Input, config.DecoderArgs)
+	data, metadata, err := AdaptDecoder(input).DecodeContext(ctx, config.Input, config.DecoderArgs)
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "error while processing input data")
 	}
@@ -81,16 +166,42 @@ func (r *Recoder) Decode(config *Config) (interface{}, interface{}, error) {
 // Encode function encodes data into config.Output stream
 // using config.Encoder.
 func (r *Recoder) Encode(config *Config, data interface{}, metadata interface{}) error {
+	return r.EncodeContext(context.Background(), config, data, metadata)
+}
+
+// EncodeContext is the same as Encode, but encoding
+// is aborted, when ctx is done.
+func (r *Recoder) EncodeContext(ctx context.Context, config *Config, data interface{}, metadata interface{}) error {
 	output, ok := r.Encoders[config.Encoder]
 	if !ok {
 		return errors.Errorf("unknown output type '%s'", config.Encoder)
 	}
-	if err := output.Encode(config.Output, data, metadata, config.EncoderArgs); err != nil {
+	var err error
+	if enc, ok := output.(configEncoder); ok {
+		err = enc.encodeConfig(ctx, config, data, metadata)
+	} else {
+		err = AdaptEncoder(output).EncodeContext(ctx, config.Output, data, metadata, config.EncoderArgs)
+	}
+	if err != nil {
 		return errors.Annotate(err, "error while processing output data")
 	}
 	return nil
 }
 
+// CoderForPath returns coder name based on file extension of path.
+func CoderForPath(path string) string {
+	return strings.TrimPrefix(filepath.Ext(path), ".")
+}
+
+// inputFileName returns name of the decoded file, used to resolve relative
+// includes, or empty string if input is not a named file, e.g. stdin.
+func inputFileName(in io.Reader) string {
+	if f, ok := in.(interface{ Name() string }); ok && in != io.Reader(os.Stdin) {
+		return f.Name()
+	}
+	return ""
+}
+
 // Coder is the common interface for encoders and decoders.
 type Coder interface {
 	Initialize() error
@@ -109,6 +220,24 @@ type Encoder interface {
 	Encode(writer io.Writer, in interface{}, metadata interface{}, args []string) error
 }
 
+// ContextDecoder is implemented by decoders, which support cancellation.
+type ContextDecoder interface {
+	Decoder
+	DecodeContext(ctx context.Context, reader io.Reader, args []string) (interface{}, interface{}, error)
+}
+
+// ContextEncoder is implemented by encoders, which support cancellation.
+type ContextEncoder interface {
+	Encoder
+	EncodeContext(ctx context.Context, writer io.Writer, in interface{}, metadata interface{}, args []string) error
+}
+
+// configEncoder is implemented by encoders, which
+// use settings of Config besides encoder arguments.
+type configEncoder interface {
+	encodeConfig(ctx context.Context, config *Config, in interface{}, metadata interface{}) error
+}
+
 // ArgumentError is used, when convter function detects argument error
 type ArgumentError struct {
 	error string
@@ -118,25 +247,19 @@ func (e ArgumentError) Error() string {
 	return e.error
 }
 
-// DefaultRecoder is a recoder with all built-in encoders and decoders.
-var DefaultRecoder *Recoder
-
-func init() {
-	DefaultRecoder = &Recoder{
-		Decoders: map[string]Decoder{},
-		Encoders: map[string]Encoder{},
-		Coders:   map[string]Coder{},
-	}
-	DefaultRecoder.Register(&coderJSON{})
-	DefaultRecoder.Register(&coderYAML{})
-	DefaultRecoder.Register(&coderHCL{})
-	DefaultRecoder.Register(&coderTOML{})
-	DefaultRecoder.Register(&coderNULL{})
+var (
+	defaultRecoder     *Recoder
+	defaultRecoderErr  error
+	defaultRecoderOnce sync.Once
+)
 
-	sess := session.New() //nolint
-	tpl := newCoderTPL(DefaultRecoder, s3.New(sess))
-	DefaultRecoder.Register(tpl)
-	if err := DefaultRecoder.Initialize(); err != nil {
-		panic(fmt.Sprintf("error: cannot initialize default recoder, %s", err))
-	}
+// Default returns a convenience recoder with all built-in encoders and
+// decoders. It is created on the first call, so importing the package
+// has no side effects, S3 client is created on first S3 import.
+// Use NewRecoder for custom configuration.
+func Default() (*Recoder, error) {
+	defaultRecoderOnce.Do(func() {
+		defaultRecoder, defaultRecoderErr = NewRecoder(WithBuiltinCoders())
+	})
+	return defaultRecoder, errors.Annotatef(defaultRecoderErr, "cannot initialize default recoder")
 }
@@ -1,10 +1,10 @@
 package fc
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/url"
-	"os"
 	"path/filepath"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -23,53 +23,83 @@ type importOpts struct {
 	nofail   bool
 	pattern  bool
 	metadata bool
+
+	// format is the name of decoder, by default
+	// decoder is selected by file extension.
+	format string
+
+	// dir is the base directory of relative file paths,
+	// current directory is used if empty.
+	dir string
+}
+
+// resolve returns path of local file relative to base directory.
+func (o importOpts) resolve(path string) string {
+	if o.dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(o.dir, path)
 }
 
+// newImporter creates importer, if s3 is nil,
+// S3 client of the recoder is used.
 func newImporter(r *Recoder, s3 s3iface.S3API) *importer {
 	return &importer{recoder: r, s3: s3}
 }
 
-func (t *importer) importURL(fileURL string, opts importOpts) (interface{}, error) {
+func (t *importer) importURL(ctx context.Context, fileURL string, opts importOpts) (interface{}, error) {
 	urlInfo, err := url.Parse(fileURL)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot import, invalid URL '%s'", fileURL)
 	}
 
+	if fn, ok := t.recoder.importers[urlInfo.Scheme]; ok {
+		if opts.pattern {
+			return nil, errors.Errorf("pattern option is not supported for %s yet", urlInfo.Scheme)
+		}
+		return t.importCustom(ctx, fn, urlInfo, opts)
+	}
+
 	switch urlInfo.Scheme {
 	case "file", "":
 		path := urlInfo.Host + urlInfo.Path
 		if opts.pattern {
-			return t.importFiles(path, opts)
+			return t.importFiles(ctx, path, opts)
 		}
-		return t.importFile(path, opts)
+		return t.importFile(ctx, path, opts)
 	case "s3":
 		if opts.pattern {
 			return nil, errors.Errorf("pattern option is not supported for s3 yet")
 		}
-		return t.importS3Object(urlInfo, opts)
+		return t.importS3Object(ctx, urlInfo, opts)
 	}
 
 	return nil, errors.Errorf("cannot import, unknown URL scheme '%s' in '%s'", urlInfo.Scheme, fileURL)
 }
 
-func (t *importer) parseBody(fileURL string, file io.ReadCloser, opts importOpts) (interface{}, interface{}, error) {
+func (t *importer) parseBody(ctx context.Context, fileURL string, file io.ReadCloser, opts importOpts) (interface{}, interface{}, error) {
 	defer file.Close()
 
 	if opts.raw {
-		body, err := ioutil.ReadAll(file)
+		body, err := ioutil.ReadAll(&ctxReader{ctx: ctx, r: file})
 		if err != nil {
 			return nil, nil, errors.Annotatef(err, "cannot read imported file '%s'", fileURL)
 		}
 		return string(body), nil, nil
 	}
 
-	ext := filepath.Ext(fileURL)[1:]
+	ext := CoderForPath(fileURL)
+	if opts.format != "" {
+		ext = opts.format
+	}
 	decoder, ok := t.recoder.Decoders[ext]
-	if !ok {
+	if !ok && opts.format != "" {
+		return nil, nil, errors.Errorf("unknown format '%s', cannot parse file '%s'", ext, fileURL)
+	} else if !ok {
 		return nil, nil, errors.Errorf("unknown file extension '%s', cannot parse file '%s'", ext, fileURL)
 	}
 
-	res, metadata, err := decoder.Decode(file, nil)
+	res, metadata, err := AdaptDecoder(decoder).DecodeContext(ctx, file, nil)
 	if err != nil {
 		return nil, nil, errors.Annotatef(err, "cannot parse imported file '%s'", fileURL)
 	}
@@ -77,7 +107,7 @@ func (t *importer) parseBody(fileURL string, file io.ReadCloser, opts importOpts
 	return res, metadata, nil
 }
 
-func (t *importer) importFile(path string, opts importOpts) (res interface{}, err error) {
+func (t *importer) importFile(ctx context.Context, path string, opts importOpts) (res interface{}, err error) {
 	var metadata = map[string]interface{}{
 		"url": path,
 	}
@@ -93,12 +123,13 @@ func (t *importer) importFile(path string, opts importOpts) (res interface{}, er
 		}
 	}()
 
-	file, err := os.Open(path)
+	t.recoder.fileAccessed(opts.resolve(path))
+	file, err := t.recoder.fileSystem().Open(opts.resolve(path))
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot open import file '%s'", path)
 	}
 
-	metadata["body"], metadata["metadata"], err = t.parseBody(path, file, opts)
+	metadata["body"], metadata["metadata"], err = t.parseBody(ctx, path, file, opts)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot parse imported file '%s'", path)
 	}
@@ -106,15 +137,22 @@ func (t *importer) importFile(path string, opts importOpts) (res interface{}, er
 	return
 }
 
-func (t *importer) importFiles(pattern string, opts importOpts) (entries []interface{}, err error) {
-	files, err := filepath.Glob(pattern)
+func (t *importer) importFiles(ctx context.Context, pattern string, opts importOpts) (entries []interface{}, err error) {
+	t.recoder.fileAccessed(opts.resolve(pattern))
+	files, err := t.recoder.fileSystem().Glob(opts.resolve(pattern))
 
 	if err != nil {
 		return nil, errors.Annotatef(err, "import failed, cannot list files")
 	}
 
 	for _, path := range files {
-		res, err := t.importFile(path, opts)
+		// keep paths relative to base directory, as in the pattern
+		if opts.dir != "" && !filepath.IsAbs(pattern) {
+			if path, err = filepath.Rel(opts.dir, path); err != nil {
+				return nil, errors.Annotatef(err, "import failed, cannot resolve file path")
+			}
+		}
+		res, err := t.importFile(ctx, path, opts)
 		if err != nil {
 			return nil, errors.Annotatef(err, "import failed, cannot import file '%s'", path)
 		}
@@ -124,7 +162,7 @@ func (t *importer) importFiles(pattern string, opts importOpts) (entries []inter
 	return entries, nil
 }
 
-func (t *importer) importS3Object(urlInfo *url.URL, opts importOpts) (res interface{}, err error) {
+func (t *importer) importS3Object(ctx context.Context, urlInfo *url.URL, opts importOpts) (res interface{}, err error) {
 	var (
 		bucket  = urlInfo.Host
 		key     = urlInfo.Path[1:]
@@ -155,12 +193,48 @@ func (t *importer) importS3Object(urlInfo *url.URL, opts importOpts) (res interf
 	if version != "" {
 		input.VersionId = aws.String(version)
 	}
-	obj, err := t.s3.GetObject(input)
+	client := t.s3
+	if client == nil {
+		if client, err = t.recoder.s3Client(); err != nil {
+			return nil, errors.Annotatef(err, "cannot import s3 file '%s'", urlInfo)
+		}
+	}
+	obj, err := client.GetObjectWithContext(ctx, input)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot import s3 file '%s'", urlInfo)
 	}
 
-	metadata["body"], metadata["metadata"], err = t.parseBody(key, obj.Body, opts)
+	metadata["body"], metadata["metadata"], err = t.parseBody(ctx, key, obj.Body, opts)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot parse imported file '%s'", urlInfo)
+	}
+
+	return
+}
+
+// importCustom imports object using handler of custom URL scheme.
+func (t *importer) importCustom(ctx context.Context, fn ImportFunc, urlInfo *url.URL, opts importOpts) (res interface{}, err error) {
+	var metadata = map[string]interface{}{
+		"url": urlInfo.String(),
+	}
+	defer func() {
+		if opts.nofail && err != nil {
+			metadata["error"] = err
+			err = nil
+		}
+		if opts.nofail || opts.metadata {
+			res = metadata
+		} else {
+			res = metadata["body"]
+		}
+	}()
+
+	body, err := fn(ctx, urlInfo)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot import '%s'", urlInfo)
+	}
+
+	metadata["body"], metadata["metadata"], err = t.parseBody(ctx, urlInfo.Path, body, opts)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot parse imported file '%s'", urlInfo)
 	}
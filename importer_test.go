@@ -2,6 +2,7 @@ package fc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	_ "fmt"
 	"io/ioutil"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/stretchr/testify/require"
@@ -16,15 +18,15 @@ import (
 
 func TestImporterFile_basic(t *testing.T) {
 	require := require.New(t)
-	importer := newImporter(DefaultRecoder, nil)
+	importer := newImporter(testRecoder, nil)
 
-	res, err := importer.importURL("file://testdata/file1.json", importOpts{})
+	res, err := importer.importURL(context.Background(), "file://testdata/file1.json", importOpts{})
 	require.NoError(err)
 	js, err := json.Marshal(res)
 	require.NoError(err)
 	require.JSONEq(`{"list":[1,2,3],"map":{"key":"value"}}`, string(js))
 
-	res, err = importer.importURL("file://testdata/file1.json", importOpts{raw: true})
+	res, err = importer.importURL(context.Background(), "file://testdata/file1.json", importOpts{raw: true})
 	require.NoError(err)
 	require.Equal(res, `{
   "list": [1,2,3],
@@ -37,9 +39,9 @@ func TestImporterFile_basic(t *testing.T) {
 
 func TestImporterFile_metadata(t *testing.T) {
 	require := require.New(t)
-	importer := newImporter(DefaultRecoder, nil)
+	importer := newImporter(testRecoder, nil)
 
-	res, err := importer.importURL("file://testdata/file1.json", importOpts{metadata: true})
+	res, err := importer.importURL(context.Background(), "file://testdata/file1.json", importOpts{metadata: true})
 	require.NoError(err)
 	data, ok := res.(map[string]interface{})
 	require.True(ok)
@@ -52,9 +54,9 @@ func TestImporterFile_metadata(t *testing.T) {
 
 func TestImporterFile_nofail(t *testing.T) {
 	require := require.New(t)
-	importer := newImporter(DefaultRecoder, nil)
+	importer := newImporter(testRecoder, nil)
 
-	res, err := importer.importURL("file://testdata/file-not-found.json", importOpts{nofail: true})
+	res, err := importer.importURL(context.Background(), "file://testdata/file-not-found.json", importOpts{nofail: true})
 	require.NoError(err)
 	data, ok := res.(map[string]interface{})
 	require.True(ok)
@@ -63,9 +65,9 @@ func TestImporterFile_nofail(t *testing.T) {
 
 func TestImporterFiles_basic(t *testing.T) {
 	require := require.New(t)
-	importer := newImporter(DefaultRecoder, nil)
+	importer := newImporter(testRecoder, nil)
 
-	res, err := importer.importURL("file://testdata/import/basic/*.json", importOpts{pattern: true})
+	res, err := importer.importURL(context.Background(), "file://testdata/import/basic/*.json", importOpts{pattern: true})
 	require.NoError(err)
 	list, ok := res.([]interface{})
 	require.True(ok)
@@ -76,12 +78,12 @@ func TestImporterFiles_basic(t *testing.T) {
 
 func TestImporterFiles_nofail(t *testing.T) {
 	require := require.New(t)
-	importer := newImporter(DefaultRecoder, nil)
+	importer := newImporter(testRecoder, nil)
 
-	_, err := importer.importURL("file://testdata/import/error/*.json", importOpts{pattern: true})
+	_, err := importer.importURL(context.Background(), "file://testdata/import/error/*.json", importOpts{pattern: true})
 	require.Error(err)
 
-	res, err := importer.importURL("file://testdata/import/error/*.json", importOpts{pattern: true, nofail: true})
+	res, err := importer.importURL(context.Background(), "file://testdata/import/error/*.json", importOpts{pattern: true, nofail: true})
 	require.NoError(err)
 	list, ok := res.([]interface{})
 	require.True(ok)
@@ -111,6 +113,10 @@ func (m *mockS3Client) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, er
 	return m.putObject(in)
 }
 
+func (m *mockS3Client) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	return m.GetObject(in)
+}
+
 func (m *mockS3Client) GetObject(in *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
 	if m.getObject == nil {
 		panic("GetObject S3 mock function is not set")
@@ -127,8 +133,8 @@ func TestImporterS3File_basic(t *testing.T) {
 			Body: ioutil.NopCloser(bytes.NewBufferString(testInput)),
 		}, nil
 	}
-	importer := newImporter(DefaultRecoder, s3client)
-	res, err := importer.importURL("s3://bucket/file.json", importOpts{})
+	importer := newImporter(testRecoder, s3client)
+	res, err := importer.importURL(context.Background(), "s3://bucket/file.json", importOpts{})
 	require.NoError(t, err)
 	js, err := json.Marshal(res)
 	require.NoError(t, err)
@@ -142,7 +148,7 @@ func TestImporterS3File_basic(t *testing.T) {
 			Body: ioutil.NopCloser(bytes.NewBufferString(testInput)),
 		}, nil
 	}
-	res, err = importer.importURL("s3://mybucket/file.json?versionId=0123456789", importOpts{metadata: true})
+	res, err = importer.importURL(context.Background(), "s3://mybucket/file.json?versionId=0123456789", importOpts{metadata: true})
 	require.NoError(t, err)
 	data := res.(map[string]interface{})
 	js, err = json.Marshal(data["body"])
@@ -0,0 +1,323 @@
+package fc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/juju/errors"
+)
+
+// LintConfig is the template linter configuration.
+type LintConfig struct {
+	// Templates is the list of template files to check.
+	Templates []string
+
+	// Sample is an optional path to sample input file,
+	// decoder is selected by file extension.
+	Sample string
+
+	// Schema is an optional path to JSON Schema of the input.
+	Schema string
+}
+
+// LintIssue is a single problem found by linter.
+type LintIssue struct {
+	Location string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Location, i.Message)
+}
+
+var (
+	lintParseError    = regexp.MustCompile(`^template: (.*?:\d+): ((?s).*)$`)
+	lintUndefinedFunc = regexp.MustCompile(`^function "([^"]+)" not defined$`)
+)
+
+type linter struct {
+	recoder *Recoder
+	funcMap map[string]interface{}
+	fields  map[string]bool
+	visited map[string]bool
+	issues  []LintIssue
+}
+
+// Lint statically checks templates for syntax errors, unknown functions,
+// unreachable defines and field references, which are not present
+// in sample input or JSON Schema. Includes with literal path are followed.
+func (r *Recoder) Lint(config *LintConfig) ([]LintIssue, error) {
+	tpl, ok := r.Coders["tpl"].(*coderTPL)
+	if !ok {
+		return nil, errors.Errorf("lint: template encoder is not registered")
+	}
+
+	l := &linter{
+		recoder: r,
+		funcMap: tpl.newFuncMap(context.Background(), nil, "", ""),
+		visited: make(map[string]bool),
+	}
+
+	imp := newImporter(r, nil)
+	if config.Sample != "" || config.Schema != "" {
+		l.fields = make(map[string]bool)
+	}
+	if config.Sample != "" {
+		sample, err := imp.importFile(context.Background(), config.Sample, importOpts{})
+		if err != nil {
+			return nil, errors.Annotatef(err, "lint: cannot read sample input")
+		}
+		collectSampleFields(sample, l.fields)
+	}
+	if config.Schema != "" {
+		schema, err := imp.importFile(context.Background(), config.Schema, importOpts{})
+		if err != nil {
+			return nil, errors.Annotatef(err, "lint: cannot read schema")
+		}
+		collectSchemaFields(schema, l.fields)
+	}
+
+	for _, path := range config.Templates {
+		if err := l.lintFile(path, tplOpts{}, ""); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	return l.issues, nil
+}
+
+func (l *linter) report(location, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// lintFile lints template at path, read errors of included
+// templates are reported as issues at location of the include.
+func (l *linter) lintFile(path string, opts tplOpts, location string) error {
+	key := path + "\x00" + opts.leftDelim + "\x00" + opts.rightDelim
+	if l.visited[key] {
+		return nil
+	}
+	l.visited[key] = true
+
+	content, err := l.recoder.readFile(path)
+	if err != nil && location != "" {
+		l.report(location, "cannot include template, %s", errors.Cause(err))
+		return nil
+	} else if err != nil {
+		return errors.Annotatef(err, "lint: cannot read template '%s'", path)
+	}
+	content, opts, err = parseTplHeader(content, opts)
+	if err != nil {
+		l.report(path+":1", "invalid header, %s", err)
+		return nil
+	}
+
+	funcMap := make(map[string]interface{}, len(l.funcMap))
+	for k, v := range l.funcMap {
+		funcMap[k] = v
+	}
+
+	// parsing stops on first undefined function, so unknown
+	// functions are stubbed one by one until template parses.
+	var tpl *template.Template
+	for {
+		tpl, err = template.New(path).
+			Delims(opts.leftDelim, opts.rightDelim).
+			Funcs(funcMap).
+			Parse(string(content))
+		if err == nil {
+			break
+		}
+		location, msg := path, strings.TrimPrefix(err.Error(), "template: ")
+		if m := lintParseError.FindStringSubmatch(err.Error()); m != nil {
+			location, msg = m[1], m[2]
+		}
+		m := lintUndefinedFunc.FindStringSubmatch(msg)
+		if m == nil {
+			l.report(location, "syntax error, %s", msg)
+			return nil
+		}
+		l.report(location, "unknown function '%s'", m[1])
+		funcMap[m[1]] = func(...interface{}) string { return "" }
+	}
+
+	referenced := make(map[string]bool)
+	for _, t := range tpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := l.walk(t.Tree, t.Tree.Root, filepath.Dir(path), referenced); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	var unreachable []string
+	for _, t := range tpl.Templates() {
+		if t.Name() != path && !referenced[t.Name()] {
+			unreachable = append(unreachable, t.Name())
+		}
+	}
+	sort.Strings(unreachable)
+	for _, name := range unreachable {
+		l.report(path, "unreachable define '%s'", name)
+	}
+
+	return nil
+}
+
+func (l *linter) walk(tree *parse.Tree, node parse.Node, dir string, referenced map[string]bool) error {
+	var err error
+	walk := func(n parse.Node) {
+		if err == nil && n != nil && !isNilNode(n) {
+			err = l.walk(tree, n, dir, referenced)
+		}
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, c := range n.Nodes {
+			walk(c)
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe)
+	case *parse.IfNode:
+		walk(n.Pipe)
+		walk(n.List)
+		walk(n.ElseList)
+	case *parse.RangeNode:
+		walk(n.Pipe)
+		walk(n.List)
+		walk(n.ElseList)
+	case *parse.WithNode:
+		walk(n.Pipe)
+		walk(n.List)
+		walk(n.ElseList)
+	case *parse.TemplateNode:
+		referenced[n.Name] = true
+		walk(n.Pipe)
+	case *parse.PipeNode:
+		for _, c := range n.Cmds {
+			walk(c)
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			walk(c)
+		}
+		err = l.followInclude(tree, n, dir)
+	case *parse.ChainNode:
+		walk(n.Node)
+		l.checkFields(tree, n, n.Field)
+	case *parse.FieldNode:
+		l.checkFields(tree, n, n.Ident)
+	case *parse.VariableNode:
+		l.checkFields(tree, n, n.Ident[1:])
+	}
+	return err
+}
+
+// followInclude lints templates included with literal path.
+func (l *linter) followInclude(tree *parse.Tree, cmd *parse.CommandNode, dir string) error {
+	if len(cmd.Args) < 2 {
+		return nil
+	}
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "include" {
+		return nil
+	}
+	path, ok := cmd.Args[1].(*parse.StringNode)
+	if !ok {
+		return nil
+	}
+	var options []string
+	if len(cmd.Args) > 3 {
+		for _, arg := range cmd.Args[3:] {
+			if s, ok := arg.(*parse.StringNode); ok {
+				options = append(options, s.Text)
+			}
+		}
+	}
+	opts, err := parseTplOptions(options...)
+	if err != nil && len(options) > 0 {
+		// trailing string can be metadata
+		opts, err = parseTplOptions(options[:len(options)-1]...)
+	}
+	if err != nil {
+		return nil
+	}
+	file := path.Text
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	location, _ := tree.ErrorContext(cmd)
+	return l.lintFile(file, opts, location)
+}
+
+func (l *linter) checkFields(tree *parse.Tree, node parse.Node, fields []string) {
+	if l.fields == nil {
+		return
+	}
+	for _, f := range fields {
+		if !l.fields[f] {
+			location, _ := tree.ErrorContext(node)
+			l.report(location, "field '%s' is not present in sample input or schema", f)
+		}
+	}
+}
+
+// isNilNode checks for typed nil nodes, e.g. empty ElseList.
+func isNilNode(n parse.Node) bool {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		return n == nil
+	case *parse.PipeNode:
+		return n == nil
+	}
+	return false
+}
+
+func collectSampleFields(in interface{}, fields map[string]bool) {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			fields[k] = true
+			collectSampleFields(e, fields)
+		}
+	case []interface{}:
+		for _, e := range v {
+			collectSampleFields(e, fields)
+		}
+	}
+}
+
+func collectSchemaFields(in interface{}, fields map[string]bool) {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		if props, ok := v["properties"].(map[string]interface{}); ok {
+			for k := range props {
+				fields[k] = true
+			}
+		}
+		if required, ok := v["required"].([]interface{}); ok {
+			for _, k := range required {
+				if s, ok := k.(string); ok {
+					fields[s] = true
+				}
+			}
+		}
+		for _, e := range v {
+			collectSchemaFields(e, fields)
+		}
+	case []interface{}:
+		for _, e := range v {
+			collectSchemaFields(e, fields)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package fc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	issues, err := testRecoder.Lint(&LintConfig{
+		Templates: []string{"testdata/lint/main.tpl"},
+		Sample:    "testdata/lint/sample.yml",
+	})
+	require.NoError(t, err)
+	var res []string
+	for _, i := range issues {
+		res = append(res, i.String())
+	}
+	require.Equal(t, []string{
+		"testdata/lint/main.tpl:4: unknown function 'unknown_func'",
+		"testdata/lint/main.tpl:5:21: field 'missing' is not present in sample input or schema",
+		"testdata/lint/sub/include.tpl:3: syntax error, unclosed action started at testdata/lint/sub/include.tpl:2",
+		"testdata/lint/sub/delims.tpl:1: unknown function 'other_func'",
+		"testdata/lint/main.tpl:8:3: cannot include template, open testdata/lint/sub/missing.tpl: no such file or directory",
+		"testdata/lint/main.tpl: unreachable define 'unused'",
+	}, res)
+
+	issues, err = testRecoder.Lint(&LintConfig{
+		Templates: []string{"testdata/lint/main.tpl"},
+		Sample:    "testdata/lint/sample.yml",
+		Schema:    "testdata/lint/schema.json",
+	})
+	require.NoError(t, err)
+	for _, i := range issues {
+		require.NotContains(t, i.Message, "field")
+	}
+
+	// absolute include paths are not joined with template directory
+	r, err := NewRecoder(WithBuiltinCoders(), WithFileSystem(memFileSystem{
+		"/tpl/main.tpl":      `{{ include "/shared/header.tpl" $ }}`,
+		"/shared/header.tpl": `{{ unknown_func }}`,
+	}))
+	require.NoError(t, err)
+	issues, err = r.Lint(&LintConfig{Templates: []string{"/tpl/main.tpl"}})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, "/shared/header.tpl:1: unknown function 'unknown_func'", issues[0].String())
+}
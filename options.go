@@ -0,0 +1,203 @@
+package fc
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/juju/errors"
+)
+
+// FileSystem provides access to local files,
+// e.g. templates, includes and imports.
+type FileSystem interface {
+	Open(name string) (io.ReadCloser, error)
+	Glob(pattern string) ([]string, error)
+}
+
+// osFileSystem is the FileSystem of the host.
+type osFileSystem struct{}
+
+func (osFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (osFileSystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (osFileSystem) WriteFile(name string, data []byte) error {
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+// WritableFileSystem is a FileSystem supporting writes,
+// it's used to update golden files of test cases.
+type WritableFileSystem interface {
+	FileSystem
+	WriteFile(name string, data []byte) error
+}
+
+// ImportFunc opens the object under URL for template import
+// function. Body is decoded by the extension of URL path.
+type ImportFunc func(ctx context.Context, u *url.URL) (io.ReadCloser, error)
+
+// Option configures Recoder created by NewRecoder.
+type Option func(r *Recoder) error
+
+// NewRecoder creates new recoder. Coders must be registered
+// explicitly with WithCoders or WithBuiltinCoders options.
+// Registered coders are initialized after applying all options.
+func NewRecoder(options ...Option) (*Recoder, error) {
+	r := &Recoder{
+		Decoders:  map[string]Decoder{},
+		Encoders:  map[string]Encoder{},
+		Coders:    map[string]Coder{},
+		funcs:     map[string]interface{}{},
+		importers: map[string]ImportFunc{},
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := r.Initialize(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
+// WithCoders registers coders.
+func WithCoders(coders ...Coder) Option {
+	return func(r *Recoder) error {
+		for _, c := range coders {
+			r.Register(c)
+		}
+		return nil
+	}
+}
+
+// WithBuiltinCoders registers all built-in coders.
+func WithBuiltinCoders() Option {
+	return func(r *Recoder) error {
+		r.Register(&coderJSON{})
+		r.Register(&coderYAML{})
+		r.Register(&coderHCL{})
+		r.Register(&coderTOML{})
+		r.Register(&coderNULL{})
+		r.Register(&coderJSONSchema{})
+		r.Register(&coderGo{})
+		r.Register(&coderINI{})
+		r.Register(&coderProperties{})
+		r.Register(&coderEnv{})
+		r.Register(&coderMsgpack{})
+		r.Register(&coderCBOR{})
+		r.Register(&coderProto{conv: r})
+		r.Register(&coderHOCON{conv: r})
+		r.Register(&coderJsonnet{conv: r})
+		r.Register(&coderCUE{conv: r})
+		r.Register(&coderFrontMatter{})
+		r.Register(&coderPlist{})
+		r.Register(&coderXLSX{})
+		r.Register(newCoderTPL(r, nil))
+		return nil
+	}
+}
+
+// WithFuncs adds functions to the template function map,
+// built-in functions with the same name are overridden.
+func WithFuncs(funcs map[string]interface{}) Option {
+	return func(r *Recoder) error {
+		for k, v := range funcs {
+			r.funcs[k] = v
+		}
+		return nil
+	}
+}
+
+// WithImporter registers handler of URL scheme for template import
+// function. Built-in schemes, 'file' and 's3', can be overridden.
+func WithImporter(scheme string, fn ImportFunc) Option {
+	return func(r *Recoder) error {
+		if scheme == "" {
+			return errors.New("import scheme is not set")
+		}
+		r.importers[scheme] = fn
+		return nil
+	}
+}
+
+// WithFileSystem sets file system of templates, includes,
+// imports and schemas, the host file system is used by default.
+func WithFileSystem(fs FileSystem) Option {
+	return func(r *Recoder) error {
+		r.fs = fs
+		return nil
+	}
+}
+
+// WithFileAccessHook sets OnFileAccess callback, which is called
+// with absolute path of every local file read by coders.
+func WithFileAccessHook(fn func(path string)) Option {
+	return func(r *Recoder) error {
+		r.OnFileAccess = fn
+		return nil
+	}
+}
+
+// WithS3Client sets S3 client of s3:// imports. By default
+// the client is created on first S3 import from AWS
+// shared configuration and environment.
+func WithS3Client(client s3iface.S3API) Option {
+	return func(r *Recoder) error {
+		r.s3 = client
+		return nil
+	}
+}
+
+func (r *Recoder) fileSystem() FileSystem {
+	if r.fs == nil {
+		return osFileSystem{}
+	}
+	return r.fs
+}
+
+// readFile reads the whole file from the recoder file system.
+func (r *Recoder) readFile(path string) ([]byte, error) {
+	file, err := r.fileSystem().Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+// writeFile writes file to the recoder file system.
+func (r *Recoder) writeFile(path string, data []byte) error {
+	fs, ok := r.fileSystem().(WritableFileSystem)
+	if !ok {
+		return errors.New("file system is read-only")
+	}
+	return fs.WriteFile(path, data)
+}
+
+// s3Client returns S3 client, creating it on first use.
+func (r *Recoder) s3Client() (s3iface.S3API, error) {
+	r.s3Once.Do(func() {
+		if r.s3 != nil {
+			return
+		}
+		sess, err := session.NewSession()
+		if err != nil {
+			r.s3Err = errors.Annotatef(err, "cannot create AWS session")
+			return
+		}
+		r.s3 = s3.New(sess)
+	})
+	return r.s3, r.s3Err
+}
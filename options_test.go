@@ -0,0 +1,109 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRecoder is the default recoder shared by tests.
+var testRecoder = func() *Recoder {
+	r, err := Default()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}()
+
+// memFileSystem is in-memory FileSystem.
+type memFileSystem map[string]string
+
+func (fs memFileSystem) Open(name string) (io.ReadCloser, error) {
+	content, ok := fs[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+func (fs memFileSystem) WriteFile(name string, data []byte) error {
+	fs[name] = string(data)
+	return nil
+}
+
+func (fs memFileSystem) Glob(pattern string) ([]string, error) {
+	var res []string
+	for name := range fs {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			res = append(res, name)
+		}
+	}
+	return res, nil
+}
+
+func TestDefault(t *testing.T) {
+	r, err := Default()
+	require.NoError(t, err)
+	require.True(t, r == testRecoder, "default recoder is created once")
+	require.Contains(t, r.Decoders, "yaml")
+}
+
+func TestNewRecoder(t *testing.T) {
+	r, err := NewRecoder()
+	require.NoError(t, err)
+	require.Empty(t, r.Coders)
+	require.Error(t, r.Run(&Config{Decoder: "json", Encoder: "json"}))
+
+	r, err = NewRecoder(WithCoders(&coderJSON{}, &coderYAML{}))
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, r.Run(&Config{
+		Decoder: "json",
+		Encoder: "yaml",
+		Input:   bytes.NewBufferString(`{"a": 1}`),
+		Output:  &out,
+	}))
+	require.Equal(t, "a: 1\n", out.String())
+
+	// S3 client is created only on first S3 import
+	require.Nil(t, testRecoder.s3)
+}
+
+func TestNewRecoderOptions(t *testing.T) {
+	r, err := NewRecoder(
+		WithBuiltinCoders(),
+		WithFileSystem(memFileSystem{
+			"/tpl/main.tpl": `{{ greet .name }} {{ (import "vars.yml").port }} {{ (import "mem://host/app.json").env }}`,
+			"/tpl/vars.yml": "port: 80\n",
+		}),
+		WithFuncs(map[string]interface{}{
+			"greet": func(name string) string { return "hello " + name },
+		}),
+		WithImporter("mem", func(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+			require.Equal(t, "/app.json", u.Path)
+			return ioutil.NopCloser(strings.NewReader(`{"env": "prod"}`)), nil
+		}),
+	)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, r.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"/tpl/main.tpl"},
+		Input:       bytes.NewBufferString(`{"name": "world"}`),
+		Output:      &out,
+	}))
+	require.Equal(t, "hello world 80 prod", out.String())
+
+	_, err = NewRecoder(WithImporter("", nil))
+	require.Error(t, err)
+}
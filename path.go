@@ -0,0 +1,344 @@
+package fc
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ParsePath parses jq-style path, e.g.: .spec.containers[0].image
+// Elements of returned path are either string map keys or int list indexes.
+// Keys with special characters can be quoted: .labels["app.kubernetes.io/name"]
+func ParsePath(s string) ([]interface{}, error) {
+	var path []interface{}
+	in := strings.TrimSpace(s)
+	if in == "" || in[0] != '.' && in[0] != '[' {
+		return nil, errors.Errorf("invalid path '%s', path must start with '.'", s)
+	}
+	if in == "." {
+		return path, nil
+	}
+	for len(in) > 0 {
+		switch {
+		case strings.HasPrefix(in, ".["):
+			in = in[1:]
+		case strings.HasPrefix(in, `."`):
+			key, rest, err := parseQuotedKey(in[1:])
+			if err != nil {
+				return nil, errors.Annotatef(err, "invalid path '%s'", s)
+			}
+			path, in = append(path, key), rest
+		case in[0] == '.':
+			end := strings.IndexAny(in[1:], ".[")
+			if end < 0 {
+				end = len(in) - 1
+			}
+			if end == 0 {
+				return nil, errors.Errorf("invalid path '%s', empty key", s)
+			}
+			path, in = append(path, in[1:end+1]), in[end+1:]
+		case strings.HasPrefix(in, `["`):
+			key, rest, err := parseQuotedKey(in[1:])
+			if err != nil || !strings.HasPrefix(rest, "]") {
+				return nil, errors.Errorf("invalid path '%s', unterminated key", s)
+			}
+			path, in = append(path, key), rest[1:]
+		case in[0] == '[':
+			end := strings.Index(in, "]")
+			if end < 0 {
+				return nil, errors.Errorf("invalid path '%s', unterminated index", s)
+			}
+			idx, err := strconv.Atoi(strings.TrimSpace(in[1:end]))
+			if err != nil {
+				return nil, errors.Errorf("invalid path '%s', invalid index '%s'", s, in[1:end])
+			}
+			path, in = append(path, idx), in[end+1:]
+		default:
+			return nil, errors.Errorf("invalid path '%s', unexpected '%s'", s, in)
+		}
+	}
+	return path, nil
+}
+
+// parseQuotedKey parses leading double-quoted string of s.
+func parseQuotedKey(s string) (string, string, error) {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == '"' {
+			key, err := strconv.Unquote(s[:i+1])
+			return key, s[i+1:], errors.Trace(err)
+		}
+	}
+	return "", "", errors.New("unterminated quoted key")
+}
+
+// listIndex resolves index of list with length n,
+// negative indexes are counted from the end.
+func listIndex(idx, n int) int {
+	if idx < 0 {
+		return n + idx
+	}
+	return idx
+}
+
+// GetPath returns value of data at path.
+func GetPath(data interface{}, path []interface{}) (interface{}, error) {
+	data = normalizeValue(data)
+	for i, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("cannot get key '%s' of non-map value at %s", key, Change{Path: path[:i]}.PathString())
+			}
+			if data, ok = m[key]; !ok {
+				return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+			}
+		case int:
+			l, ok := data.([]interface{})
+			if !ok {
+				return nil, errors.Errorf("cannot get index %d of non-list value at %s", key, Change{Path: path[:i]}.PathString())
+			}
+			idx := listIndex(key, len(l))
+			if idx < 0 || idx >= len(l) {
+				return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+			}
+			data = l[idx]
+		}
+	}
+	return data, nil
+}
+
+// SetPath sets value of data at path and returns modified data.
+// Missing maps are created, lists can be extended by setting
+// value at index equal to the list length.
+func SetPath(data interface{}, path []interface{}, value interface{}) (interface{}, error) {
+	return setPath(normalizeValue(data), path, 0, value, false)
+}
+
+// DeletePath deletes value of data at path and returns modified data.
+func DeletePath(data interface{}, path []interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return setPath(normalizeValue(data), path, 0, nil, true)
+}
+
+func setPath(data interface{}, path []interface{}, i int, value interface{}, del bool) (interface{}, error) {
+	if i == len(path) {
+		return value, nil
+	}
+	last := i == len(path)-1
+
+	switch key := path[i].(type) {
+	case string:
+		if data == nil && !del {
+			data = map[string]interface{}{}
+		}
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("cannot set key '%s' of non-map value at %s", key, Change{Path: path[:i]}.PathString())
+		}
+		if del && last {
+			delete(m, key)
+			return m, nil
+		}
+		if _, ok := m[key]; !ok && del {
+			return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+		}
+		v, err := setPath(m[key], path, i+1, value, del)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		m[key] = v
+		return m, nil
+	case int:
+		if data == nil && !del {
+			data = []interface{}{}
+		}
+		l, ok := data.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("cannot set index %d of non-list value at %s", key, Change{Path: path[:i]}.PathString())
+		}
+		idx := listIndex(key, len(l))
+		if idx == len(l) && !del {
+			l = append(l, nil)
+		}
+		if idx < 0 || idx >= len(l) {
+			return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+		}
+		if del && last {
+			return append(l[:idx], l[idx+1:]...), nil
+		}
+		v, err := setPath(l[idx], path, i+1, value, del)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		l[idx] = v
+		return l, nil
+	}
+	return nil, errors.Errorf("invalid path element '%v'", path[i])
+}
+
+// SetYAMLPath sets value at path of YAML document src and returns the
+// edited document. Unlike SetPath of decoded data, key order and comments
+// are preserved, only indentation is normalized.
+func SetYAMLPath(src []byte, path []interface{}, value interface{}) ([]byte, error) {
+	var v yamlv3.Node
+	if err := v.Encode(value); err != nil {
+		return nil, errors.Annotatef(err, "YAML: cannot encode value")
+	}
+	return editYAML(src, path, &v, false)
+}
+
+// DeleteYAMLPath deletes value at path of YAML document src
+// and returns the edited document, see SetYAMLPath.
+func DeleteYAMLPath(src []byte, path []interface{}) ([]byte, error) {
+	return editYAML(src, path, nil, true)
+}
+
+func editYAML(src []byte, path []interface{}, value *yamlv3.Node, del bool) ([]byte, error) {
+	var doc yamlv3.Node
+	dec := yamlv3.NewDecoder(bytes.NewReader(src))
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return nil, errors.Annotatef(err, "YAML: cannot decode")
+	}
+	// other documents would be lost on writing
+	var next yamlv3.Node
+	if err := dec.Decode(&next); err != io.EOF {
+		return nil, errors.New("YAML: multi-document streams cannot be edited")
+	}
+	var root *yamlv3.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	} else {
+		// empty document
+		doc.Kind = yamlv3.DocumentNode
+	}
+	if del && len(path) == 0 {
+		value = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	root, err := setYAMLNode(root, path, 0, value, del)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc.Content = []*yamlv3.Node{root}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(&doc); err != nil {
+		return nil, errors.Annotatef(err, "YAML: cannot encode")
+	}
+	return buf.Bytes(), errors.Trace(enc.Close())
+}
+
+// setYAMLNode is setPath of YAML nodes, comments of replaced values are kept.
+func setYAMLNode(node *yamlv3.Node, path []interface{}, i int, value *yamlv3.Node, del bool) (*yamlv3.Node, error) {
+	if i == len(path) {
+		if node != nil {
+			value.HeadComment, value.LineComment, value.FootComment = node.HeadComment, node.LineComment, node.FootComment
+		}
+		return value, nil
+	}
+	last := i == len(path)-1
+	if node != nil && node.Kind == yamlv3.AliasNode {
+		// anchored value and other aliases are kept intact
+		node = copyYAMLNode(node.Alias)
+	}
+	if node != nil && node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" && !del {
+		node = nil
+	}
+
+	switch key := path[i].(type) {
+	case string:
+		if node == nil && !del {
+			node = &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+		}
+		if node == nil || node.Kind != yamlv3.MappingNode {
+			return nil, errors.Errorf("cannot set key '%s' of non-map value at %s", key, Change{Path: path[:i]}.PathString())
+		}
+		idx := -1
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				idx = j + 1
+				break
+			}
+		}
+		if idx < 0 {
+			if del {
+				return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+			}
+			v, err := setYAMLNode(nil, path, i+1, value, del)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, v)
+			return node, nil
+		}
+		if del && last {
+			node.Content = deleteYAMLNodes(node.Content, idx-1, 2)
+			return node, nil
+		}
+		v, err := setYAMLNode(node.Content[idx], path, i+1, value, del)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		node.Content[idx] = v
+		return node, nil
+	case int:
+		if node == nil && !del {
+			node = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+		}
+		if node == nil || node.Kind != yamlv3.SequenceNode {
+			return nil, errors.Errorf("cannot set index %d of non-list value at %s", key, Change{Path: path[:i]}.PathString())
+		}
+		idx := listIndex(key, len(node.Content))
+		if idx == len(node.Content) && !del {
+			node.Content = append(node.Content, nil)
+		}
+		if idx < 0 || idx >= len(node.Content) {
+			return nil, errors.NotFoundf("%s", Change{Path: path[:i+1]}.PathString())
+		}
+		if del && last {
+			node.Content = deleteYAMLNodes(node.Content, idx, 1)
+			return node, nil
+		}
+		v, err := setYAMLNode(node.Content[idx], path, i+1, value, del)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		node.Content[idx] = v
+		return node, nil
+	}
+	return nil, errors.Errorf("invalid path element '%v'", path[i])
+}
+
+// deleteYAMLNodes deletes n nodes at idx, head comment of deleted
+// nodes, e.g. comment of the document, is moved to the next node.
+func deleteYAMLNodes(nodes []*yamlv3.Node, idx, n int) []*yamlv3.Node {
+	if comment := nodes[idx].HeadComment; comment != "" && idx+n < len(nodes) {
+		next := nodes[idx+n]
+		next.HeadComment = strings.TrimSpace(comment + "\n" + next.HeadComment)
+	}
+	return append(nodes[:idx], nodes[idx+n:]...)
+}
+
+// copyYAMLNode returns deep copy of node without anchor,
+// nested aliases keep pointing to their anchors.
+func copyYAMLNode(node *yamlv3.Node) *yamlv3.Node {
+	res := *node
+	res.Anchor = ""
+	if node.Kind != yamlv3.AliasNode && len(node.Content) > 0 {
+		res.Content = make([]*yamlv3.Node, len(node.Content))
+		for i, n := range node.Content {
+			res.Content[i] = copyYAMLNode(n)
+		}
+	}
+	return &res
+}
@@ -0,0 +1,117 @@
+package fc
+
+import (
+	"testing"
+
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	for in, exp := range map[string][]interface{}{
+		".":                        nil,
+		".a":                       {"a"},
+		".a.b[0]":                  {"a", "b", 0},
+		".[1][-1]":                 {1, -1},
+		`.labels["app.io/name"].x`: {"labels", "app.io/name", "x"},
+		`."a b".c`:                 {"a b", "c"},
+	} {
+		path, err := ParsePath(in)
+		require.NoError(t, err, in)
+		require.Equal(t, exp, path, in)
+	}
+	for _, in := range []string{"", "a", ".a..b", ".a[x]", `.["a`, ".a[0"} {
+		_, err := ParsePath(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestPathEdit(t *testing.T) {
+	data := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "v1"},
+		"list":  []interface{}{1, 2, 3},
+		"debug": true,
+	}
+
+	v, err := GetPath(data, []interface{}{"list", -1})
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	_, err = GetPath(data, []interface{}{"image", "name"})
+	require.True(t, errors.IsNotFound(err))
+	_, err = GetPath(data, []interface{}{"debug", 0})
+	require.Error(t, err)
+
+	res, err := SetPath(data, []interface{}{"image", "tag"}, "v2")
+	require.NoError(t, err)
+	res, err = SetPath(res, []interface{}{"list", 3}, 4)
+	require.NoError(t, err)
+	res, err = SetPath(res, []interface{}{"new", "key"}, "value")
+	require.NoError(t, err)
+	res, err = DeletePath(res, []interface{}{"debug"})
+	require.NoError(t, err)
+	res, err = DeletePath(res, []interface{}{"list", 0})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "v2"},
+		"list":  []interface{}{2, 3, 4},
+		"new":   map[string]interface{}{"key": "value"},
+	}, res)
+
+	_, err = SetPath(res, []interface{}{"list", 5}, 1)
+	require.True(t, errors.IsNotFound(err))
+	_, err = DeletePath(res, []interface{}{"missing", "key"})
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestYAMLPathEdit(t *testing.T) {
+	src := []byte(`# app config
+name: billing
+image:
+  tag: v1 # pinned
+  repo: registry.local/billing
+ports: [8080, 9090]
+debug: true
+`)
+
+	res, err := SetYAMLPath(src, []interface{}{"image", "tag"}, "v2")
+	require.NoError(t, err)
+	res, err = SetYAMLPath(res, []interface{}{"ports", 2}, 9091)
+	require.NoError(t, err)
+	res, err = SetYAMLPath(res, []interface{}{"env", "MODE"}, map[string]interface{}{"value": "prod"})
+	require.NoError(t, err)
+	res, err = DeleteYAMLPath(res, []interface{}{"debug"})
+	require.NoError(t, err)
+	res, err = DeleteYAMLPath(res, []interface{}{"name"})
+	require.NoError(t, err)
+	require.Equal(t, `# app config
+image:
+  tag: v2 # pinned
+  repo: registry.local/billing
+ports: [8080, 9090, 9091]
+env:
+  MODE:
+    value: prod
+`, string(res))
+
+	res, err = SetYAMLPath(nil, []interface{}{"list", 0}, "a")
+	require.NoError(t, err)
+	require.Equal(t, "list:\n  - a\n", string(res))
+
+	// editing through alias does not change the anchor
+	res, err = SetYAMLPath([]byte("base: &base\n  a: 1\n  b: [1]\nx: *base\ny: *base\n"), []interface{}{"x", "a"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, "base: &base\n  a: 1\n  b: [1]\nx:\n  a: 2\n  b: [1]\ny: *base\n", string(res))
+
+	_, err = SetYAMLPath([]byte("a: 1 # c\nb: 2\n---\na: 3\n"), []interface{}{"a"}, 5)
+	require.Contains(t, err.Error(), "YAML: multi-document streams cannot be edited")
+	_, err = DeleteYAMLPath([]byte("a: 1\n---\n"), []interface{}{"a"})
+	require.Contains(t, err.Error(), "YAML: multi-document streams cannot be edited")
+	res, err = SetYAMLPath([]byte("---\na: 1\n"), []interface{}{"a"}, 5)
+	require.NoError(t, err)
+	require.Equal(t, "a: 5\n", string(res))
+
+	_, err = SetYAMLPath(src, []interface{}{"name", "first"}, "x")
+	require.Contains(t, err.Error(), "cannot set key 'first' of non-map value at .name")
+	_, err = DeleteYAMLPath(src, []interface{}{"image", "missing"})
+	require.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,131 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// PluginPrefix is the file name prefix of external coder executables,
+// e.g. gofc-coder-csv provides 'csv' decoder and encoder.
+//
+// Plugins communicate via stdin and stdout. Decoding runs
+// "gofc-coder-NAME decode ARGS..." with raw input on stdin and
+// expects JSON document on stdout. Encoding runs
+// "gofc-coder-NAME encode ARGS..." with JSON document on stdin
+// and expects encoded output on stdout. Non-zero exit status
+// is reported as error with the content of stderr.
+const PluginPrefix = "gofc-coder-"
+
+// coderPlugin is a coder, implemented by external executable.
+type coderPlugin struct {
+	name string
+	path string
+}
+
+func (c *coderPlugin) Initialize() error {
+	return nil
+}
+
+func (c *coderPlugin) Names() []string {
+	return []string{c.name}
+}
+
+func (c *coderPlugin) Decode(in io.Reader, args []string) (interface{}, interface{}, error) {
+	return c.DecodeContext(context.Background(), in, args)
+}
+
+func (c *coderPlugin) DecodeContext(ctx context.Context, in io.Reader, args []string) (interface{}, interface{}, error) {
+	var out bytes.Buffer
+	if err := c.run(ctx, "decode", args, in, &out); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var data interface{}
+	if err := json.NewDecoder(&out).Decode(&data); err != nil {
+		return nil, nil, errors.Annotatef(err, "%s: cannot decode plugin output", c.name)
+	}
+	return data, nil, nil
+}
+
+func (c *coderPlugin) Encode(out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	return c.EncodeContext(context.Background(), out, in, metadata, args)
+}
+
+func (c *coderPlugin) EncodeContext(ctx context.Context, out io.Writer, in interface{}, metadata interface{}, args []string) error {
+	js, err := json.Marshal(normalizeValue(in))
+	if err != nil {
+		return errors.Annotatef(err, "%s: cannot encode plugin input", c.name)
+	}
+	return errors.Trace(c.run(ctx, "encode", args, bytes.NewReader(js), out))
+}
+
+func (c *coderPlugin) run(ctx context.Context, command string, args []string, in io.Reader, out io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.path, append([]string{command}, args...)...)
+	cmd.Stdin = in
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return errors.Trace(ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.Errorf("%s: plugin failed: %s", c.name, msg)
+		}
+		return errors.Annotatef(err, "%s: plugin failed", c.name)
+	}
+	return nil
+}
+
+// LoadPlugins registers external coders found in dirs. Executables
+// named with PluginPrefix are registered as both decoder and encoder,
+// coders which are already registered are not overridden. If the
+// same plugin is found in several dirs, the first one is used.
+func (r *Recoder) LoadPlugins(dirs ...string) error {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot list plugins in '%s'", dir)
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(file.Name(), ".exe")
+			executable := file.Mode()&0111 != 0 || name != file.Name()
+			if !strings.HasPrefix(name, PluginPrefix) || name == PluginPrefix || file.IsDir() || !executable {
+				continue
+			}
+			name = strings.TrimPrefix(name, PluginPrefix)
+			if _, ok := r.Coders[name]; ok {
+				continue
+			}
+			r.Register(&coderPlugin{name: name, path: filepath.Join(dir, file.Name())})
+		}
+	}
+	return nil
+}
+
+// PluginDirs returns directories, which are searched for plugins
+// by gofc: directories of GOFC_PLUGIN_PATH and PATH variables.
+func PluginDirs() []string {
+	dirs := filepath.SplitList(os.Getenv("GOFC_PLUGIN_PATH"))
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// WithPlugins registers external coders found in dirs, see LoadPlugins.
+func WithPlugins(dirs ...string) Option {
+	return func(r *Recoder) error {
+		return errors.Trace(r.LoadPlugins(dirs...))
+	}
+}
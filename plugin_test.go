@@ -0,0 +1,48 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugins(t *testing.T) {
+	r, err := NewRecoder(
+		WithBuiltinCoders(),
+		WithPlugins("./testdata/plugins", "./testdata/missing"),
+	)
+	require.NoError(t, err)
+	require.Contains(t, r.Decoders, "kv")
+	require.Contains(t, r.Encoders, "kv")
+	require.NotContains(t, r.Coders, "noexec")
+
+	var out bytes.Buffer
+	require.NoError(t, r.Run(&Config{
+		Decoder:     "kv",
+		Encoder:     "kv",
+		EncoderArgs: []string{"generated"},
+		Input:       bytes.NewBufferString("a=1\nb=x y\n"),
+		Output:      &out,
+	}))
+	require.Equal(t, "# generated\n{\"a\":\"1\",\"b\":\"x y\"}", out.String())
+
+	out.Reset()
+	require.NoError(t, r.Run(&Config{
+		Decoder:     "null",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/plugins/plugin.tpl"},
+		Output:      &out,
+	}))
+	require.Equal(t, "value", out.String())
+
+	err = r.Run(&Config{
+		Decoder:     "kv",
+		DecoderArgs: []string{"fail"},
+		Encoder:     "json",
+		Input:       bytes.NewBufferString("a=1\n"),
+		Output:      &out,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "kv: plugin failed: invalid input")
+}
@@ -0,0 +1 @@
+{{ import "./import/basic/file1.json" | toJson }}
\ No newline at end of file
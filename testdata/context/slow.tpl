@@ -0,0 +1 @@
+{{ (import "slow://host/data.json").key }}
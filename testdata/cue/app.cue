@@ -0,0 +1,14 @@
+import "strings"
+
+// application configuration
+#Port: int & >0 & <65536
+
+name:   "billing"
+image:  "registry.local/\(name):\(version)"
+version: "1.4.2"
+ports: [...#Port] & [8080, 9090]
+env: {
+	MODE:  strings.ToUpper("production")
+	DEBUG: *false | bool
+}
+_hidden: 1
@@ -0,0 +1,12 @@
+#Config: {
+	name:     string & =~"^[a-z]+$"
+	replicas: int & >=1 | *1
+	log: {
+		level:  "debug" | "info" | *"warn" | "error"
+		format: *"text" | "json"
+	}
+	ports: [...int & >0 & <65536]
+	tags?: [string]: string
+}
+
+#Config
@@ -0,0 +1 @@
+args: <% $.name %> {{ x }}
@@ -0,0 +1 @@
+default: {{ $.name }}
\ No newline at end of file
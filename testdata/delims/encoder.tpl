@@ -0,0 +1 @@
+encoder: << $.name >> {{ x }}
\ No newline at end of file
@@ -0,0 +1,5 @@
+# gofc: delims=[[ ]]
+name: [[ $.name ]]
+value: {{ literal }}
+[[ include "./args.tpl" $ "delims=<% %>" ]]
+[[ include "./default.tpl" $ -]]
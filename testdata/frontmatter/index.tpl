@@ -0,0 +1,5 @@
+{{- range $doc := import "docs/*.md" "metadata,pattern,format=frontmatter" }}
+{{- if not $doc.body.draft }}
+- [{{ $doc.body.title }}]({{ base $doc.url }}): {{ $doc.metadata | trim | splitList "\n" | first }}
+{{- end }}
+{{- end }}
@@ -0,0 +1,2 @@
+{{ $app := import "app.conf" "format=hocon" -}}
+{{ $app.app.name }} {{ $app.akka.loglevel }} {{ $app.service.retries }}
@@ -0,0 +1,5 @@
+{{- if $.invalid -}}
+{{ include "./metadata.tpl" $ 1 "delims=[[ ]]" }}
+{{- else -}}
+{{ include "./metadata.tpl" $ (dict "env" "prod") }}
+{{- end -}}
@@ -0,0 +1,5 @@
+{{- if $.invalid -}}
+{{ include "./metadata.tpl" $ "bogus" "meta" }}
+{{- else -}}
+{{ include "./metadata.tpl" $ "meta" }}
+{{- end -}}
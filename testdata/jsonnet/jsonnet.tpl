@@ -0,0 +1,2 @@
+{{ $app := import "lib/defaults.libsonnet" "format=jsonnet" -}}
+timeout: {{ $app.timeout }}
@@ -0,0 +1 @@
+{ timeout: '10s' }
@@ -0,0 +1,8 @@
+{{ define "used" }}{{ .name }}{{ end -}}
+{{ define "unused" }}unused{{ end -}}
+{{ template "used" $ }}
+{{ $.name | unknown_func }}
+{{ range $.list }}{{ .missing }}{{ end }}
+{{ include "./sub/include.tpl" $ }}
+{{ include "./sub/delims.tpl" $ "delims=[[ ]]" }}
+{{ include "./sub/missing.tpl" $ }}
@@ -0,0 +1 @@
+[[ $.list | other_func ]]
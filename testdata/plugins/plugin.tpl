@@ -0,0 +1 @@
+{{ (decode_kv "key=value").key }}
\ No newline at end of file
@@ -0,0 +1,3 @@
+{{ range $.upstreams -}}
+server {{ . }};
+{{ end -}}
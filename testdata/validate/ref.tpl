@@ -0,0 +1,2 @@
+{{- $data := validate "ref.yml" . -}}
+port={{ $data.port }}
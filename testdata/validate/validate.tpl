@@ -0,0 +1,2 @@
+{{- $data := validate "schema.yml" . -}}
+name={{ $data.name }}
@@ -0,0 +1,208 @@
+package fc
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+)
+
+// TestCaseSuffix is the file name suffix of template test cases.
+const TestCaseSuffix = ".gofc-test.yml"
+
+// TestCase describes single golden-file test.
+// All paths are relative to the test case file.
+type TestCase struct {
+	Path string `yaml:"-"`
+
+	Input       string   `yaml:"input"`
+	Decoder     string   `yaml:"decoder"`
+	DecoderArgs []string `yaml:"decoder_args"`
+
+	Template    string   `yaml:"template"`
+	Encoder     string   `yaml:"encoder"`
+	EncoderArgs []string `yaml:"encoder_args"`
+
+	Expected string `yaml:"expected"`
+}
+
+// TestResult is the result of test case run.
+type TestResult struct {
+	Case *TestCase
+
+	// Diff is the unified diff between expected and
+	// actual output, empty if test passed.
+	Diff string
+
+	// Updated is set if golden file was regenerated.
+	Updated bool
+}
+
+// Passed reports whether test output matched golden file.
+func (r *TestResult) Passed() bool {
+	return r.Diff == ""
+}
+
+// FindTestCases returns test case files under provided paths
+// in the recoder file system. Paths can be either directories or
+// test case files.
+//
+// FileSystem has no directory listing, so directories are searched
+// with Glob one level at a time, until a level has no entries.
+func (r *Recoder) FindTestCases(paths ...string) ([]string, error) {
+	fs := r.fileSystem()
+	var res []string
+	for _, root := range paths {
+		var found []string
+		var entries int
+		pattern := globEscape(root)
+		for {
+			pattern = filepath.Join(pattern, "*")
+			matches, err := fs.Glob(pattern)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot find test cases in '%s'", root)
+			} else if len(matches) == 0 {
+				break
+			}
+			entries += len(matches)
+			for _, path := range matches {
+				if strings.HasSuffix(path, TestCaseSuffix) {
+					found = append(found, path)
+				}
+			}
+		}
+		if entries == 0 {
+			// root is either a test case file or an empty directory
+			if _, err := r.readFile(root); err == nil {
+				found = append(found, root)
+			} else if matches, _ := fs.Glob(globEscape(root)); len(matches) == 0 {
+				return nil, errors.Annotatef(err, "cannot find test cases in '%s'", root)
+			}
+		}
+		sort.Strings(found)
+		res = append(res, found...)
+	}
+	return res, nil
+}
+
+// globEscape escapes pattern characters of path.
+func globEscape(path string) string {
+	return globEscaper.Replace(path)
+}
+
+var globEscaper = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+
+// LoadTestCase reads test case file from the recoder file system.
+func (r *Recoder) LoadTestCase(path string) (*TestCase, error) {
+	content, err := r.readFile(path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read test case '%s'", path)
+	}
+	tc := &TestCase{Path: path}
+	if err = yaml.UnmarshalStrict(content, tc); err != nil {
+		return nil, errors.Annotatef(err, "cannot parse test case '%s'", path)
+	}
+	if tc.Expected == "" {
+		return nil, errors.Errorf("test case '%s': expected output file is not set", path)
+	}
+
+	dir := filepath.Dir(path)
+	if tc.Input != "" {
+		tc.Input = filepath.Join(dir, tc.Input)
+		if tc.Decoder == "" {
+			tc.Decoder = CoderForPath(tc.Input)
+		}
+	} else if tc.Decoder == "" {
+		tc.Decoder = "null"
+	}
+	if tc.Template != "" {
+		tc.Template = filepath.Join(dir, tc.Template)
+		if tc.Encoder == "" {
+			tc.Encoder = "tpl"
+		}
+	}
+	if tc.Encoder == "" {
+		return nil, errors.Errorf("test case '%s': neither encoder nor template is set", path)
+	}
+	tc.Expected = filepath.Join(dir, tc.Expected)
+
+	return tc, nil
+}
+
+// RunTest renders test case and compares output with golden file,
+// files are read from and written to the recoder file system.
+// If update is set, golden file is overwritten with actual output instead.
+func (r *Recoder) RunTest(tc *TestCase, update bool) (*TestResult, error) {
+	var input bytes.Buffer
+	if tc.Input != "" {
+		content, err := r.readFile(tc.Input)
+		if err != nil {
+			return nil, errors.Annotatef(err, "test case '%s': cannot read input", tc.Path)
+		}
+		input.Write(content)
+	}
+
+	encoderArgs := tc.EncoderArgs
+	if tc.Template != "" {
+		encoderArgs = append([]string{tc.Template}, encoderArgs...)
+	}
+
+	var output bytes.Buffer
+	err := r.Run(&Config{
+		Decoder:     tc.Decoder,
+		DecoderArgs: tc.DecoderArgs,
+		Encoder:     tc.Encoder,
+		EncoderArgs: encoderArgs,
+		Input:       &input,
+		Output:      &output,
+	})
+	if err != nil {
+		return nil, errors.Annotatef(err, "test case '%s': cannot render", tc.Path)
+	}
+
+	res := &TestResult{Case: tc}
+	if update {
+		if err = r.writeFile(tc.Expected, output.Bytes()); err != nil {
+			return nil, errors.Annotatef(err, "test case '%s': cannot update golden file", tc.Path)
+		}
+		res.Updated = true
+		return res, nil
+	}
+
+	expected, err := r.readFile(tc.Expected)
+	if err != nil {
+		return nil, errors.Annotatef(err, "test case '%s': cannot read golden file", tc.Path)
+	}
+	if bytes.Equal(expected, output.Bytes()) {
+		return res, nil
+	}
+
+	res.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(expected)),
+		B:        splitLines(output.String()),
+		FromFile: tc.Expected,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, errors.Annotatef(err, "test case '%s': cannot generate diff", tc.Path)
+	}
+	if res.Diff == "" {
+		// difference is not visible in line based diff, e.g. trailing newline
+		res.Diff = "output differs from golden file\n"
+	}
+	return res, nil
+}
+
+// splitLines splits s into lines, keeping line endings.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
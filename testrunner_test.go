@@ -0,0 +1,105 @@
+package fc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTest(t *testing.T) {
+	cases, err := testRecoder.FindTestCases("testdata/test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"testdata/test/basic.gofc-test.yml"}, cases)
+
+	files, err := testRecoder.FindTestCases("testdata/test-failing/json.gofc-test.yml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"testdata/test-failing/json.gofc-test.yml"}, files)
+
+	_, err = testRecoder.FindTestCases("testdata/missing")
+	require.Error(t, err)
+
+	tc, err := testRecoder.LoadTestCase(cases[0])
+	require.NoError(t, err)
+	require.Equal(t, "yml", tc.Decoder)
+	require.Equal(t, "tpl", tc.Encoder)
+	res, err := testRecoder.RunTest(tc, false)
+	require.NoError(t, err)
+	require.True(t, res.Passed())
+
+	// failing case is kept apart, so that 'gofc test testdata/test' passes
+	tc, err = testRecoder.LoadTestCase("testdata/test-failing/json.gofc-test.yml")
+	require.NoError(t, err)
+	res, err = testRecoder.RunTest(tc, false)
+	require.NoError(t, err)
+	require.False(t, res.Passed())
+	require.Equal(t, `--- testdata/test-failing/json.golden
++++ actual
+@@ -1,6 +1,6 @@
+ {
+   "upstreams": [
+     "us1",
+-    "us3"
++    "us2"
+   ]
+ }
+`, res.Diff)
+}
+
+func TestRunTestUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofc-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tc, err := testRecoder.LoadTestCase("testdata/test-failing/json.gofc-test.yml")
+	require.NoError(t, err)
+	tc.Expected = filepath.Join(dir, "json.golden")
+
+	res, err := testRecoder.RunTest(tc, true)
+	require.NoError(t, err)
+	require.True(t, res.Updated)
+
+	res, err = testRecoder.RunTest(tc, false)
+	require.NoError(t, err)
+	require.True(t, res.Passed())
+}
+
+func TestRunTestFileSystem(t *testing.T) {
+	fs := memFileSystem{
+		"/t/case.gofc-test.yml":  "input: input.json\ntemplate: main.tpl\nexpected: main.golden\n",
+		"/t/input.json":          `{"name": "web"}`,
+		"/t/main.tpl":            "name={{ .name }}",
+		"/t/main.golden":         "name=api",
+		"/t/sub/a.gofc-test.yml": "expected: a.golden\n",
+	}
+	r, err := NewRecoder(WithBuiltinCoders(), WithFileSystem(fs))
+	require.NoError(t, err)
+
+	cases, err := r.FindTestCases("/t")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/t/case.gofc-test.yml", "/t/sub/a.gofc-test.yml"}, cases)
+
+	tc, err := r.LoadTestCase("/t/case.gofc-test.yml")
+	require.NoError(t, err)
+	res, err := r.RunTest(tc, false)
+	require.NoError(t, err)
+	require.False(t, res.Passed())
+
+	res, err = r.RunTest(tc, true)
+	require.NoError(t, err)
+	require.True(t, res.Updated)
+	require.Equal(t, "name=web", fs["/t/main.golden"])
+
+	// golden files of read-only file system cannot be updated
+	r, err = NewRecoder(WithBuiltinCoders(), WithFileSystem(readOnlyFileSystem{fs}))
+	require.NoError(t, err)
+	_, err = r.RunTest(tc, true)
+	require.Contains(t, err.Error(), "cannot update golden file: file system is read-only")
+}
+
+// readOnlyFileSystem hides WriteFile of the file system.
+type readOnlyFileSystem struct {
+	FileSystem
+}
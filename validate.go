@@ -0,0 +1,280 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Schema is a compiled JSON Schema. Drafts 2019-09 and 2020-12
+// are supported, draft is selected by $schema keyword and
+// defaults to 2020-12.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// SchemaViolation is a single validation failure.
+type SchemaViolation struct {
+	// Path to invalid value, elements are either
+	// string map keys or int list indexes.
+	Path []interface{}
+
+	Message string
+
+	// Line of invalid value in the source, 0 if unknown.
+	// Lines are known for YAML input only, see LocateYAML.
+	Line int
+}
+
+func (v SchemaViolation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", v.Line, Change{Path: v.Path}.PathString(), v.Message)
+	}
+	return fmt.Sprintf("%s: %s", Change{Path: v.Path}.PathString(), v.Message)
+}
+
+// ValidationError is returned, when data does not conform to the schema.
+type ValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return "validation failed:\n" + strings.Join(lines, "\n")
+}
+
+// LocateYAML sets Line of violations from YAML source of validated
+// data. With multi set, data is the list of documents of multi-document
+// stream, as decoded by YAML decoder with 'multi' argument. Values
+// missing in the source, e.g. required keys, are located at their
+// closest parent.
+func (e *ValidationError) LocateYAML(src []byte, multi bool) {
+	var docs []*yamlv3.Node
+	dec := yamlv3.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		// empty documents are skipped by decoder
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+		docs = append(docs, doc.Content[0])
+	}
+	for i, v := range e.Violations {
+		path := v.Path
+		var root *yamlv3.Node
+		if multi {
+			idx, ok := 0, false
+			if len(path) > 0 {
+				idx, ok = path[0].(int)
+			}
+			if !ok || idx < 0 || idx >= len(docs) {
+				continue
+			}
+			root, path = docs[idx], path[1:]
+		} else if len(docs) > 0 {
+			root = docs[0]
+		} else {
+			continue
+		}
+		e.Violations[i].Line = yamlLine(root, path)
+	}
+}
+
+// yamlLine returns line of value at path in node, line of the key
+// is returned for map values.
+func yamlLine(node *yamlv3.Node, path []interface{}) int {
+	line := node.Line
+	for _, p := range path {
+		if node.Kind == yamlv3.AliasNode {
+			node = node.Alias
+		}
+		switch key := p.(type) {
+		case string:
+			if node.Kind != yamlv3.MappingNode {
+				return line
+			}
+			var value *yamlv3.Node
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == key {
+					line, value = node.Content[j].Line, node.Content[j+1]
+					break
+				}
+			}
+			if value == nil {
+				return line
+			}
+			node = value
+		case int:
+			if node.Kind != yamlv3.SequenceNode || key < 0 || key >= len(node.Content) {
+				return line
+			}
+			node = node.Content[key]
+			line = node.Line
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+// CompileSchema compiles decoded JSON Schema document.
+// Relative references are resolved against current directory.
+func CompileSchema(schema interface{}) (*Schema, error) {
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return compileSchema(filepath.Join(dir, "schema.json"), schema)
+}
+
+// LoadSchema reads and compiles JSON Schema from path,
+// decoder is selected by file extension, so schema can
+// be written in any supported format.
+func (r *Recoder) LoadSchema(path string) (*Schema, error) {
+	schema, err := newImporter(r, nil).importFile(context.Background(), path, importOpts{})
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read schema")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return compileSchema(abs, schema)
+}
+
+func compileSchema(path string, schema interface{}) (*Schema, error) {
+	js, err := json.Marshal(normalizeValue(schema))
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot encode schema")
+	}
+	return compileSchemaJSON(path, js)
+}
+
+func compileSchemaJSON(path string, js []byte) (*Schema, error) {
+	url := "file://" + filepath.ToSlash(path)
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(url, bytes.NewReader(js)); err != nil {
+		return nil, errors.Annotatef(err, "invalid schema")
+	}
+	s, err := compiler.Compile(url)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid schema")
+	}
+	return &Schema{schema: s}, nil
+}
+
+// schemaCache caches compiled schemas by path and content,
+// so that changed schema files are compiled again.
+type schemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*Schema
+}
+
+// compile returns compiled schema, relative
+// references are resolved against path.
+func (c *schemaCache) compile(path string, schema interface{}) (*Schema, error) {
+	js, err := json.Marshal(normalizeValue(schema))
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot encode schema")
+	}
+	key := path + "\x00" + string(js)
+
+	c.mu.Lock()
+	s, ok := c.schemas[key]
+	c.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	if s, err = compileSchemaJSON(path, js); err != nil {
+		return nil, errors.Trace(err)
+	}
+	c.mu.Lock()
+	if c.schemas == nil {
+		c.schemas = make(map[string]*Schema)
+	}
+	c.schemas[key] = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+// Validate validates data against the schema. If data is invalid,
+// returned error is *ValidationError.
+func (s *Schema) Validate(data interface{}) error {
+	// validator accepts only JSON types,
+	// so data is converted via JSON first
+	js, err := json.Marshal(normalizeValue(data))
+	if err != nil {
+		return errors.Annotatef(err, "cannot encode data")
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc interface{}
+	if err = dec.Decode(&doc); err != nil {
+		return errors.Annotatef(err, "cannot encode data")
+	}
+
+	err = s.schema.Validate(doc)
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return errors.Trace(err)
+	}
+	res := &ValidationError{}
+	collectViolations(doc, verr, res)
+	sort.SliceStable(res.Violations, func(i, j int) bool {
+		return Change{Path: res.Violations[i].Path}.PathString() < Change{Path: res.Violations[j].Path}.PathString()
+	})
+	return res
+}
+
+// collectViolations flattens error tree into leaf violations.
+func collectViolations(doc interface{}, err *jsonschema.ValidationError, res *ValidationError) {
+	if len(err.Causes) == 0 {
+		res.Violations = append(res.Violations, SchemaViolation{
+			Path:    pointerPath(doc, err.InstanceLocation),
+			Message: err.Message,
+		})
+	}
+	for _, cause := range err.Causes {
+		collectViolations(doc, cause, res)
+	}
+}
+
+// pointerPath converts JSON Pointer into path,
+// doc is used to distinguish list indexes from map keys.
+func pointerPath(doc interface{}, pointer string) []interface{} {
+	var path []interface{}
+	if pointer == "" {
+		return path
+	}
+	for _, p := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		p = strings.NewReplacer("~1", "/", "~0", "~").Replace(p)
+		switch v := doc.(type) {
+		case []interface{}:
+			if idx, err := strconv.Atoi(p); err == nil && idx >= 0 && idx < len(v) {
+				path, doc = append(path, idx), v[idx]
+				continue
+			}
+		case map[string]interface{}:
+			doc = v[p]
+		}
+		path = append(path, p)
+	}
+	return path
+}
@@ -0,0 +1,158 @@
+package fc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	schema, err := testRecoder.LoadSchema("./testdata/validate/schema.yml")
+	require.NoError(t, err)
+
+	data, _, err := testRecoder.Decode(&Config{
+		Decoder: "yaml",
+		Input: bytes.NewBufferString(`
+name: web
+spec:
+  replicas: 0
+  ports: [80, 70000]
+`),
+	})
+	require.NoError(t, err)
+
+	err = schema.Validate(data)
+	require.IsType(t, &ValidationError{}, err)
+	var res []string
+	for _, v := range err.(*ValidationError).Violations {
+		res = append(res, v.String())
+	}
+	require.ElementsMatch(t, []string{
+		".spec.replicas: must be >= 1 but found 0",
+		".spec.ports[1]: must be <= 65535 but found 70000",
+	}, res)
+
+	require.NoError(t, schema.Validate(map[string]interface{}{
+		"name": "web",
+		"spec": map[string]interface{}{"ports": []int{80}},
+	}))
+}
+
+func TestValidateRun(t *testing.T) {
+	schema, err := CompileSchema(map[string]interface{}{
+		"required": []string{"name"},
+	})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = testRecoder.Run(&Config{
+		Decoder: "json",
+		Encoder: "yaml",
+		Input:   bytes.NewBufferString(`{"spec": {}}`),
+		Output:  &out,
+		Schema:  schema,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ".: missing properties: 'name'")
+	require.Empty(t, out.String())
+}
+
+func TestValidateLocateYAML(t *testing.T) {
+	schema, err := testRecoder.LoadSchema("./testdata/validate/schema.yml")
+	require.NoError(t, err)
+
+	src := `# web
+name: web
+spec:
+  replicas: 0
+  ports:
+    - 80
+    - 70000
+`
+	err = testRecoder.Run(&Config{
+		Decoder: "yaml",
+		Encoder: "json",
+		Input:   bytes.NewBufferString(src),
+		Output:  &bytes.Buffer{},
+		Schema:  schema,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 4: .spec.replicas: must be >= 1 but found 0")
+	require.Contains(t, err.Error(), "line 7: .spec.ports[1]: must be <= 65535 but found 70000")
+
+	// missing values are located at parent, documents of
+	// multi-document streams are indexed by the first path element
+	verr := &ValidationError{Violations: []SchemaViolation{
+		{Path: []interface{}{0, "spec"}},
+		{Path: []interface{}{1, "spec", "ports"}},
+		{Path: []interface{}{1, "spec", "ports", 5}},
+		{Path: []interface{}{2}},
+		{Path: []interface{}{}},
+	}}
+	verr.LocateYAML([]byte("name: a\n---\n---\nbase: &base\n  ports: [1]\nspec: *base\n"), true)
+	var lines []int
+	for _, v := range verr.Violations {
+		lines = append(lines, v.Line)
+	}
+	require.Equal(t, []int{1, 5, 5, 0, 0}, lines)
+}
+
+func TestTPLValidate(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/validate/validate.tpl"},
+		Input:       bytes.NewBufferString(`{"name": "web", "spec": {"replicas": 2}}`),
+		Output:      &out,
+	}))
+	require.Equal(t, "name=web\n", out.String())
+
+	err := testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/validate/validate.tpl"},
+		Input:       bytes.NewBufferString(`{"name": 1, "spec": {}}`),
+		Output:      &out,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ".name: expected string, but got number")
+
+	// references are resolved against schema file directory,
+	// schema compiled on the first call is reused
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		require.NoError(t, testRecoder.Run(&Config{
+			Decoder:     "json",
+			Encoder:     "tpl",
+			EncoderArgs: []string{"./testdata/validate/ref.tpl"},
+			Input:       bytes.NewBufferString(`{"port": 80}`),
+			Output:      &out,
+		}))
+		require.Equal(t, "port=80\n", out.String())
+	}
+	err = testRecoder.Run(&Config{
+		Decoder:     "json",
+		Encoder:     "tpl",
+		EncoderArgs: []string{"./testdata/validate/ref.tpl"},
+		Input:       bytes.NewBufferString(`{"port": 70000}`),
+		Output:      &out,
+	})
+	require.Contains(t, err.Error(), ".port: must be <= 65535 but found 70000")
+}
+
+func TestSchemaCache(t *testing.T) {
+	var cache schemaCache
+	doc := map[string]interface{}{"type": "string"}
+	s1, err := cache.compile("/schema.json", doc)
+	require.NoError(t, err)
+	s2, err := cache.compile("/schema.json", map[string]interface{}{"type": "string"})
+	require.NoError(t, err)
+	require.True(t, s1 == s2, "schema of the same content is compiled once")
+
+	s3, err := cache.compile("/schema.json", map[string]interface{}{"type": "integer"})
+	require.NoError(t, err)
+	require.False(t, s1 == s3, "changed schema is compiled again")
+	require.Error(t, s3.Validate("a"))
+}